@@ -11,12 +11,16 @@ import (
 	"github.com/inscription-c/explorer-api/dao"
 	"github.com/inscription-c/explorer-api/dao/indexer"
 	"github.com/inscription-c/explorer-api/handle"
+	"github.com/inscription-c/explorer-api/keystore"
 	"github.com/inscription-c/explorer-api/log"
 	"github.com/inscription-c/explorer-api/runner"
+	"github.com/inscription-c/explorer-api/stream"
 	"github.com/inscription-c/explorer-api/tables"
 	"github.com/spf13/cobra"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 var Cmd = &cobra.Command{
@@ -33,7 +37,7 @@ var Cmd = &cobra.Command{
 var configFilePath string
 
 func init() {
-	Cmd.Flags().StringVarP(&configFilePath, "config", "c", "./config/config.yaml", "config file path")
+	Cmd.PersistentFlags().StringVarP(&configFilePath, "config", "c", "./config/config.yaml", "config file path")
 }
 
 func main() {
@@ -46,6 +50,12 @@ func ExplorerApi() error {
 	if err := config.Init(configFilePath); err != nil {
 		return err
 	}
+	if publicUrl := config.Cfg.Server.PublicUrl; publicUrl != "" {
+		u, err := url.Parse(publicUrl)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.Trim(u.Path, "/") != "" {
+			return fmt.Errorf("server.public_url %s must be an http or https origin", publicUrl)
+		}
+	}
 	if config.Cfg.Server.Testnet {
 		util.ActiveNet = &netparams.TestNet3Params
 	}
@@ -54,42 +64,58 @@ func ExplorerApi() error {
 	logFile := btcutil.AppDataDir(logDir, false)
 	log.InitLogRotator(logFile)
 
-	db, err := dao.NewDB(
-		dao.WithAddr(config.Cfg.DB.Mysql.Addr),
-		dao.WithUser(config.Cfg.DB.Mysql.User),
-		dao.WithPassword(config.Cfg.DB.Mysql.Password),
-		dao.WithDBName(config.Cfg.DB.Mysql.DB),
-		dao.WithAutoMigrateTables(tables.Tables...),
-	)
+	db, err := newDB()
 	if err != nil {
 		return err
 	}
+	keyring, err := keystore.Load(config.Cfg.Keystore.KeyFile, config.Cfg.Keystore.KeyEnv)
+	if err != nil {
+		return err
+	}
+	hdKey, err := loadHDKey()
+	if err != nil {
+		return err
+	}
+	if !keyring.Enabled() && !hdKey.Enabled() {
+		log.Log.Warn("no master key nor hd key, the reveal private keys are stored as plaintext")
+	}
 
+	if config.Cfg.DB.Mysql.Addr != config.Cfg.DB.Indexer.Addr {
+		log.Log.Warn("the explorer and indexer databases are on different servers, the rarity filter can't join the rarities")
+	}
 	indexerDB, err := indexer.NewDB(
 		indexer.WithAddr(config.Cfg.DB.Indexer.Addr),
 		indexer.WithUser(config.Cfg.DB.Indexer.User),
 		indexer.WithPassword(config.Cfg.DB.Indexer.Password),
 		indexer.WithDBName(config.Cfg.DB.Indexer.DB),
+		indexer.WithExplorerDBName(config.Cfg.DB.Mysql.DB),
 	)
 	if err != nil {
 		return err
 	}
 
-	cli, err := rpcclient.NewClient(
-		rpcclient.WithClientHost(config.Cfg.Chain.Url),
-		rpcclient.WithClientUser(config.Cfg.Chain.Username),
-		rpcclient.WithClientPassword(config.Cfg.Chain.Password),
-	)
+	cli, err := newRpcClient()
 	if err != nil {
 		return err
 	}
 
 	// runner
+	streamHub := stream.NewHub(config.Cfg.Server.StreamSize)
 	blockRunner := runner.NewRunner(
 		runner.WithClient(cli),
 		runner.WithDB(db),
 		runner.WithIndexerDB(indexerDB),
 		runner.WithStartHeight(config.Cfg.Chain.StartHeight),
+		runner.WithOrderExpireAfter(config.Cfg.Order.ExpireAfter),
+		runner.WithRefundFeeRate(config.Cfg.Order.RefundFeeRate),
+		runner.WithBumpAfter(config.Cfg.Order.BumpAfter),
+		runner.WithMaxFeeRate(config.Cfg.Order.MaxFeeRate),
+		runner.WithMinPostage(config.Cfg.Order.MinPostage),
+		runner.WithMempoolReveal(config.Cfg.Order.MempoolReveal),
+		runner.WithWebhookMaxAttempts(config.Cfg.Webhook.MaxAttempts),
+		runner.WithStream(streamHub),
+		runner.WithKeyring(keyring),
+		runner.WithHDKey(hdKey),
 	)
 	blockRunner.Start()
 	signal.AddInterruptHandler(func() {
@@ -103,6 +129,9 @@ func ExplorerApi() error {
 		handle.WithClient(cli),
 		handle.WithDB(db),
 		handle.WithIndexerDB(indexerDB),
+		handle.WithStream(streamHub),
+		handle.WithKeyring(keyring),
+		handle.WithHDKey(hdKey),
 	)
 	if err != nil {
 		return err
@@ -112,3 +141,30 @@ func ExplorerApi() error {
 	}
 	return nil
 }
+
+func newDB() (*dao.DB, error) {
+	return dao.NewDB(
+		dao.WithAddr(config.Cfg.DB.Mysql.Addr),
+		dao.WithUser(config.Cfg.DB.Mysql.User),
+		dao.WithPassword(config.Cfg.DB.Mysql.Password),
+		dao.WithDBName(config.Cfg.DB.Mysql.DB),
+		dao.WithAutoMigrateTables(tables.Tables...),
+	)
+}
+
+func newRpcClient() (*rpcclient.Client, error) {
+	return rpcclient.NewClient(
+		rpcclient.WithClientHost(config.Cfg.Chain.Url),
+		rpcclient.WithClientUser(config.Cfg.Chain.Username),
+		rpcclient.WithClientPassword(config.Cfg.Chain.Password),
+	)
+}
+
+// loadHDKey loads the HD key of the reveal keys, the coin type of its paths is 1 on testnet.
+func loadHDKey() (*keystore.HDKey, error) {
+	coinType := uint32(0)
+	if config.Cfg.Server.Testnet {
+		coinType = 1
+	}
+	return keystore.LoadHDKey(config.Cfg.Keystore.HDKeyFile, config.Cfg.Keystore.HDKeyEnv, coinType)
+}
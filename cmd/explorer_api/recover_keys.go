@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcwallet/netparams"
+	"github.com/inscription-c/cins/pkg/util"
+	"github.com/inscription-c/explorer-api/config"
+	"github.com/inscription-c/explorer-api/runner"
+	"github.com/inscription-c/explorer-api/tables"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var (
+	recoverFrom    uint64
+	recoverTo      uint64
+	recoverSweepTo string
+	recoverFeeRate int64
+)
+
+var recoverKeysCmd = &cobra.Command{
+	Use:   "recover-keys",
+	Short: "rescan the derivation paths of the reveal keys for unspent outputs",
+	Long: "Derive the reveal key of every path from the HD key, check it against the reveal address of its order,\n" +
+		"and list the outputs to the reveal addresses that are not spent yet by one scan of the UTXO set of the node.\n" +
+		"This is not a recovery from the seed alone: the reveal address commits to the reveal script, so the orders\n" +
+		"in the DB with their reveal txs (reveal_tx_raw) are needed besides the HD key, a path without an order is\n" +
+		"skipped. With --sweep-to the unspent outputs of the expired, failed and succeeded orders are sent to an\n" +
+		"address, the other orders are left to the runner.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := RecoverKeys(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	recoverKeysCmd.Flags().Uint64Var(&recoverFrom, "from", 1, "first index of the paths")
+	recoverKeysCmd.Flags().Uint64Var(&recoverTo, "to", 0, "last index of the paths, 0 is the last allocated index")
+	recoverKeysCmd.Flags().StringVar(&recoverSweepTo, "sweep-to", "", "address the unspent outputs of the expired, failed and succeeded orders are sent to")
+	recoverKeysCmd.Flags().Int64Var(&recoverFeeRate, "fee-rate", 0, "fee rate of the sweep txs in sat/kvB, 0 is the refund fee rate")
+	Cmd.AddCommand(recoverKeysCmd)
+}
+
+func RecoverKeys() error {
+	if err := config.Init(configFilePath); err != nil {
+		return err
+	}
+	if config.Cfg.Server.Testnet {
+		util.ActiveNet = &netparams.TestNet3Params
+	}
+	if recoverSweepTo != "" {
+		if _, err := btcutil.DecodeAddress(recoverSweepTo, util.ActiveNet.Params); err != nil {
+			return fmt.Errorf("invalid sweep-to address: %w", err)
+		}
+	}
+	hdKey, err := loadHDKey()
+	if err != nil {
+		return err
+	}
+	if !hdKey.Enabled() {
+		return errors.New("no hd key")
+	}
+	db, err := newDB()
+	if err != nil {
+		return err
+	}
+	cli, err := newRpcClient()
+	if err != nil {
+		return err
+	}
+	r := runner.NewRunner(
+		runner.WithClient(cli),
+		runner.WithDB(db),
+		runner.WithHDKey(hdKey),
+		runner.WithRefundFeeRate(config.Cfg.Order.RefundFeeRate),
+	)
+
+	to := recoverTo
+	if to == 0 {
+		if to, err = db.LastHDKeyIndex(); err != nil {
+			return err
+		}
+	}
+
+	paths := make(map[string]string)
+	orders := make([]*tables.InscribeOrder, 0)
+	for index := recoverFrom; index <= to; index++ {
+		path, err := hdKey.Path(index)
+		if err != nil {
+			return err
+		}
+		order, err := db.GetInscribeOrderByRevealKeyPath(path)
+		if err != nil {
+			return err
+		}
+		if order.Id == 0 {
+			continue
+		}
+		paths[order.OrderId] = path
+		orders = append(orders, &order)
+	}
+	unspent, err := r.RecoverOrders(orders)
+	if err != nil {
+		return err
+	}
+
+	found, total := 0, int64(0)
+	for _, order := range orders {
+		payments := unspent[order.OrderId]
+		if len(payments) == 0 {
+			continue
+		}
+
+		path := paths[order.OrderId]
+		value := int64(0)
+		for _, payment := range payments {
+			value += payment.Value
+			fmt.Printf("%s %s %s %s:%d %d\n", path, order.OrderId, order.RevealAddress, payment.TxId, payment.Index, payment.Value)
+		}
+		found++
+		total += value
+		if recoverSweepTo == "" {
+			continue
+		}
+		txId, err := r.SweepOrder(order, payments, recoverSweepTo, recoverFeeRate)
+		if err != nil {
+			fmt.Printf("%s %s sweep failed: %s\n", path, order.OrderId, err)
+			continue
+		}
+		fmt.Printf("%s %s swept %d by %s\n", path, order.OrderId, value, txId)
+	}
+	fmt.Printf("scanned paths %d to %d, %d orders have %d sats unspent\n", recoverFrom, to, found, total)
+	return nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/inscription-c/explorer-api/config"
+	"github.com/inscription-c/explorer-api/keystore"
+	"github.com/inscription-c/explorer-api/tables"
+	"github.com/spf13/cobra"
+	"os"
+	"regexp"
+)
+
+// reencryptBatchSize is how many orders are read at a time by reencrypt-keys.
+const reencryptBatchSize = 500
+
+// undoLogRevealPriKey matches the reveal private key assignment of the order updates saved in the undo logs
+// before the keys were left out of them.
+var undoLogRevealPriKey = regexp.MustCompile(",\\s*`reveal_pri_key`='[^']*'|`reveal_pri_key`='[^']*',\\s*")
+
+var reencryptKeysCmd = &cobra.Command{
+	Use:   "reencrypt-keys",
+	Short: "encrypt the reveal private keys and callback secrets of the orders by the current master key",
+	Long: "Encrypt the plaintext reveal private keys and callback secrets, and those encrypted by an old master key,\n" +
+		"by the current master key.\n" +
+		"The keys are also removed from the order updates saved in the undo logs. It fails if any key is left\n" +
+		"on a plaintext or an old master key, run it again until it succeeds before an old master key is removed.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := ReencryptKeys(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	Cmd.AddCommand(reencryptKeysCmd)
+}
+
+func ReencryptKeys() error {
+	if err := config.Init(configFilePath); err != nil {
+		return err
+	}
+	keyring, err := keystore.Load(config.Cfg.Keystore.KeyFile, config.Cfg.Keystore.KeyEnv)
+	if err != nil {
+		return err
+	}
+	if !keyring.Enabled() {
+		return errors.New("no master key")
+	}
+	db, err := newDB()
+	if err != nil {
+		return err
+	}
+
+	reencrypted, left := 0, 0
+	afterId := uint64(0)
+	for {
+		orders, err := db.FindInscribeOrderKeysAfter(afterId, reencryptBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(orders) == 0 {
+			break
+		}
+		afterId = orders[len(orders)-1].Id
+
+		for _, order := range orders {
+			values := []struct {
+				value  string
+				update func(*tables.InscribeOrder, string) (bool, error)
+			}{
+				{order.RevealPriKey, db.UpdateInscribeOrderRevealPriKey},
+				{order.CallbackSecret, db.UpdateInscribeOrderCallbackSecret},
+			}
+			for _, v := range values {
+				if v.value == "" || !keyring.NeedsReencrypt(v.value) {
+					continue
+				}
+				plaintext, err := keyring.Decrypt(v.value)
+				if err != nil {
+					return fmt.Errorf("order %s: %w", order.OrderId, err)
+				}
+				encrypted, err := keyring.Encrypt(plaintext)
+				if err != nil {
+					return err
+				}
+				ok, err := v.update(order, encrypted)
+				if err != nil {
+					return err
+				}
+				if ok {
+					reencrypted++
+				} else {
+					left++
+				}
+			}
+		}
+	}
+	fmt.Printf("re-encrypted %d keys and secrets by master key version %d, %d changed meanwhile\n",
+		reencrypted, keyring.Current(), left)
+
+	logs, err := db.FindUndoLogsContaining("`reveal_pri_key`=")
+	if err != nil {
+		return err
+	}
+	for _, undoLog := range logs {
+		if err := db.UpdateUndoLogSql(undoLog.Id, undoLogRevealPriKey.ReplaceAllString(undoLog.Sql, "")); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("removed the keys from %d undo logs\n", len(logs))
+
+	total, err := db.CountInscribeOrderKeysWithoutPrefix(keyring.CurrentPrefix())
+	if err != nil {
+		return err
+	}
+	if total > 0 {
+		return fmt.Errorf("%d orders have keys or secrets not on master key version %d yet, run it again", total, keyring.Current())
+	}
+	return nil
+}
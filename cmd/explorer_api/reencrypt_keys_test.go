@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestUndoLogRevealPriKey(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{
+			"middle column",
+			"UPDATE `inscribe_order` SET `order_id`='a',`reveal_pri_key`='enc:1:x:y',`status`=2 WHERE `id` = 1",
+			"UPDATE `inscribe_order` SET `order_id`='a',`status`=2 WHERE `id` = 1",
+		},
+		{
+			"last column",
+			"UPDATE `inscribe_order` SET `order_id`='a',`reveal_pri_key`='0a0b' WHERE `id` = 1",
+			"UPDATE `inscribe_order` SET `order_id`='a' WHERE `id` = 1",
+		},
+		{
+			"first column",
+			"UPDATE `inscribe_order` SET `reveal_pri_key`='0a0b',`status`=2 WHERE `id` = 1",
+			"UPDATE `inscribe_order` SET `status`=2 WHERE `id` = 1",
+		},
+		{
+			"no key",
+			"UPDATE `inscribe_order` SET `status`=2 WHERE `id` = 1",
+			"UPDATE `inscribe_order` SET `status`=2 WHERE `id` = 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := undoLogRevealPriKey.ReplaceAllString(tt.sql, ""); got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,17 +3,22 @@ package config
 import (
 	"gopkg.in/yaml.v3"
 	"os"
+	"time"
 )
 
 var Cfg = &Config{}
 
 type Config struct {
 	Server struct {
-		Name        string `yaml:"name"`
-		Testnet     bool   `yaml:"testnet"`
-		RpcListen   string `yaml:"rpc_listen"`
-		EnablePProf bool   `yaml:"pprof"`
-		Prometheus  bool   `yaml:"prometheus"`
+		Name            string            `yaml:"name"`
+		Testnet         bool              `yaml:"testnet"`
+		RpcListen       string            `yaml:"rpc_listen"`
+		EnablePProf     bool              `yaml:"pprof"`
+		Prometheus      bool              `yaml:"prometheus"`
+		StreamSize      int               `yaml:"stream_buffer_size"`
+		AdminAccounts   map[string]string `yaml:"admin_accounts"`
+		ModelViewerFile string            `yaml:"model_viewer_file"`
+		PublicUrl       string            `yaml:"public_url"`
 	} `yaml:"server"`
 	Chain struct {
 		Url         string `yaml:"url"`
@@ -25,6 +30,24 @@ type Config struct {
 		Mysql   Mysql `yaml:"mysql"`
 		Indexer Mysql `yaml:"indexer"`
 	} `yaml:"db"`
+	Order struct {
+		ExpireAfter   time.Duration `yaml:"expire_after"`
+		RefundFeeRate int64         `yaml:"refund_fee_rate"`
+		BumpAfter     time.Duration `yaml:"bump_after"`
+		MaxFeeRate    int64         `yaml:"max_fee_rate"`
+		MinPostage    int64         `yaml:"min_postage"`
+		MempoolReveal bool          `yaml:"mempool_reveal"`
+	} `yaml:"order"`
+	Webhook struct {
+		MaxAttempts int               `yaml:"max_attempts"`
+		Accounts    map[string]string `yaml:"accounts"`
+	} `yaml:"webhook"`
+	Keystore struct {
+		KeyFile   string `yaml:"key_file"`
+		KeyEnv    string `yaml:"key_env"`
+		HDKeyFile string `yaml:"hd_key_file"`
+		HDKeyEnv  string `yaml:"hd_key_env"`
+	} `yaml:"keystore"`
 	Sentry struct {
 		Dsn              string  `yaml:"dsn"`
 		TracesSampleRate float64 `yaml:"traces_sample_rate"`
@@ -3,6 +3,7 @@ package constants
 import (
 	"fmt"
 	"regexp"
+	"strings"
 )
 
 const (
@@ -372,3 +373,26 @@ var Medias = []Media{
 		[]Extension{ExtensionWebm},
 	},
 }
+
+// MediaByExtension return the media of a file extension, the extension may start with a dot and is case insensitive.
+func MediaByExtension(ext string) (Media, bool) {
+	ext = strings.ToLower(strings.TrimPrefix(ext, "."))
+	for _, media := range Medias {
+		for _, extension := range media.Extensions {
+			if string(extension) == ext {
+				return media, true
+			}
+		}
+	}
+	return Media{}, false
+}
+
+// MediaByContentType return the media of a content type.
+func MediaByContentType(t ContentType) (Media, bool) {
+	for _, media := range Medias {
+		if media.ContentType == t {
+			return media, true
+		}
+	}
+	return Media{}, false
+}
@@ -0,0 +1,109 @@
+package dao
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/inscription-c/explorer-api/tables"
+	"gorm.io/gorm"
+)
+
+// LastCBRC20ParserInfo retrieves the last block parsed for c-brc-20 mints.
+func (d *DB) LastCBRC20ParserInfo() (info tables.CBRC20ParserInfo, err error) {
+	err = d.Order("height desc").First(&info).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// FindCBRC20ParserInfos retrieves the kept parsed blocks in descending order of height.
+func (d *DB) FindCBRC20ParserInfos() (list []*tables.CBRC20ParserInfo, err error) {
+	err = d.Order("height desc").Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// CreateCBRC20Block saves the tokens and mints of a parsed block and the block itself,
+// only the latest keep blocks are kept for reorg detection.
+func (d *DB) CreateCBRC20Block(info *tables.CBRC20ParserInfo, tokens []*tables.CBRC20Token, mints []*tables.CBRC20Mint, keep uint32) error {
+	if len(tokens) > 0 {
+		if err := d.Create(&tokens).Error; err != nil {
+			return err
+		}
+	}
+	if len(mints) > 0 {
+		if err := d.Create(&mints).Error; err != nil {
+			return err
+		}
+	}
+	if err := d.Create(info).Error; err != nil {
+		return err
+	}
+	if info.Height < keep {
+		return nil
+	}
+	return d.Where("height < ?", info.Height-keep).Delete(&tables.CBRC20ParserInfo{}).Error
+}
+
+// DeleteCBRC20From deletes the tokens, mints and parsed blocks from a height, they are parsed again after a reorg.
+func (d *DB) DeleteCBRC20From(height uint32) error {
+	if err := d.Where("height >= ?", height).Delete(&tables.CBRC20Token{}).Error; err != nil {
+		return err
+	}
+	if err := d.Where("height >= ?", height).Delete(&tables.CBRC20Mint{}).Error; err != nil {
+		return err
+	}
+	return d.Where("height >= ?", height).Delete(&tables.CBRC20ParserInfo{}).Error
+}
+
+// SumCBRC20Minted sums the minted amount of a token.
+func (d *DB) SumCBRC20Minted(tkid string) (total uint64, err error) {
+	var totalNull *sql.NullInt64
+	err = d.Model(&tables.CBRC20Mint{}).Select("sum(amount)").Where("tkid = ?", tkid).Scan(&totalNull).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	if totalNull != nil {
+		total = uint64(totalNull.Int64)
+	}
+	return
+}
+
+// SumCBRC20AmountByOwner sums the minted amount of every token held by an address.
+func (d *DB) SumCBRC20AmountByOwner(owner string) (list []*tables.ProtocolAmount, err error) {
+	err = d.Model(&tables.CBRC20Mint{}).Select("tkid, ticker, sum(amount) as amount").
+		Where("owner = ?", owner).Group("tkid, ticker").Order("amount desc").Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// CBRC20Holder is the balance of a token holder.
+type CBRC20Holder struct {
+	Owner  string `gorm:"column:owner"`
+	Amount uint64 `gorm:"column:amount"`
+}
+
+// FindCBRC20Holders retrieves a page of holders of a token in descending order of balance.
+// It returns one more than size entries so that the caller can tell if there are more pages.
+func (d *DB) FindCBRC20Holders(tkid string, page, size int) (list []*CBRC20Holder, err error) {
+	err = d.Model(&tables.CBRC20Mint{}).Select("owner, sum(amount) as amount").
+		Where("tkid = ?", tkid).Group("owner").Order("amount desc, owner asc").
+		Offset((page - 1) * size).Limit(size + 1).Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// FindCBRC20Mints retrieves the mints of a token in inscribe order from an offset.
+func (d *DB) FindCBRC20Mints(tkid string, offset, limit int) (list []*tables.CBRC20Mint, err error) {
+	err = d.Where("tkid = ?", tkid).Order("sequence_num asc").Offset(offset).Limit(limit).Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
@@ -0,0 +1,57 @@
+package dao
+
+import (
+	"errors"
+	"github.com/inscription-c/explorer-api/tables"
+	"gorm.io/gorm"
+)
+
+const (
+	CBRC20TokenSortDeployTime = "deploy_time"
+	CBRC20TokenSortHolders    = "holders"
+	CBRC20TokenSortProgress   = "progress"
+)
+
+// CBRC20TokenDetail is a token with its mint statistic.
+type CBRC20TokenDetail struct {
+	tables.CBRC20Token `gorm:"embedded"`
+	Minted             uint64 `gorm:"column:minted"`
+	MintCount          int64  `gorm:"column:mint_count"`
+	Holders            int64  `gorm:"column:holders"`
+}
+
+// cbrc20TokenDetails joins the tokens with the mint statistic.
+func (d *DB) cbrc20TokenDetails() *gorm.DB {
+	mints := d.Model(&tables.CBRC20Mint{}).
+		Select("tkid, sum(amount) as minted, count(*) as mint_count, count(distinct owner) as holders").
+		Group("tkid")
+	return d.Table("cbrc20_token t").
+		Select("t.*, coalesce(m.minted, 0) as minted, coalesce(m.mint_count, 0) as mint_count, coalesce(m.holders, 0) as holders").
+		Joins("left join (?) m on m.tkid = t.tkid", mints)
+}
+
+// FindCBRC20Tokens retrieves a page of tokens in descending order of the sort field.
+// It returns one more than size entries so that the caller can tell if there are more pages.
+func (d *DB) FindCBRC20Tokens(sort string, page, size int) (list []*CBRC20TokenDetail, err error) {
+	db := d.cbrc20TokenDetails()
+	switch sort {
+	case CBRC20TokenSortHolders:
+		db = db.Order("holders desc")
+	case CBRC20TokenSortProgress:
+		db = db.Order("case when t.max = 0 then 0 else coalesce(m.minted, 0) / t.max end desc")
+	}
+	err = db.Order("t.sequence_num desc").Offset((page - 1) * size).Limit(size + 1).Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// GetCBRC20TokenByTicker retrieves a token with its mint statistic by ticker.
+func (d *DB) GetCBRC20TokenByTicker(ticker string) (token CBRC20TokenDetail, err error) {
+	err = d.cbrc20TokenDetails().Where("t.ticker = ?", ticker).Take(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
@@ -0,0 +1,20 @@
+package dao
+
+import (
+	"github.com/inscription-c/explorer-api/tables"
+)
+
+// NextHDKeyIndex allocates a new index of the reveal keys derived from the HD key.
+func (d *DB) NextHDKeyIndex() (uint64, error) {
+	index := &tables.HDKeyIndex{}
+	if err := d.Create(index).Error; err != nil {
+		return 0, err
+	}
+	return index.Id, nil
+}
+
+// LastHDKeyIndex return the last allocated index of the reveal keys derived from the HD key, 0 if there's none.
+func (d *DB) LastHDKeyIndex() (index uint64, err error) {
+	err = d.Model(&tables.HDKeyIndex{}).Select("COALESCE(MAX(id), 0)").Scan(&index).Error
+	return
+}
@@ -3,6 +3,7 @@ package indexer
 import (
 	"errors"
 	"github.com/inscription-c/cins/inscription/index/tables"
+	tables2 "github.com/inscription-c/explorer-api/tables"
 	"gorm.io/gorm"
 )
 
@@ -17,3 +18,52 @@ func (d *DB) BlockHeight() (height uint32, err error) {
 	height = block.Height
 	return
 }
+
+// LatestBlockInfo retrieves the last indexed block.
+// It returns the block info and any error encountered.
+func (d *DB) LatestBlockInfo() (block tables2.BlockInfo, err error) {
+	err = d.DB.Last(&block).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// FirstBlockInfo retrieves the first indexed block.
+// It returns the block info and any error encountered.
+func (d *DB) FirstBlockInfo() (block tables2.BlockInfo, err error) {
+	err = d.DB.First(&block).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// GetBlockInfoByHeight retrieves an indexed block by its height.
+// It returns the block info and any error encountered.
+func (d *DB) GetBlockInfoByHeight(height uint32) (block tables2.BlockInfo, err error) {
+	err = d.Where("height=?", height).First(&block).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// FindLatestBlockInfos retrieves a page of the most recent indexed blocks in descending order of height.
+// One more block is returned so that the caller can tell the inscriptions number of the last block.
+func (d *DB) FindLatestBlockInfos(page, size int) (list []*tables2.BlockInfo, err error) {
+	err = d.Order("height desc").Offset((page - 1) * size).Limit(size + 1).Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// FindBlockInfosAfter retrieves the indexed blocks above a height in ascending order of height.
+func (d *DB) FindBlockInfosAfter(height uint32, limit int) (list []*tables2.BlockInfo, err error) {
+	err = d.Where("height>?", height).Order("height asc").Limit(limit).Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
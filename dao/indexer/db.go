@@ -26,6 +26,7 @@ type DBOptions struct {
 	user              string
 	password          string
 	dbName            string
+	explorerDBName    string
 	autoMigrateTables []interface{}
 }
 
@@ -60,6 +61,14 @@ func WithDBName(dbName string) DBOption {
 	}
 }
 
+// WithExplorerDBName returns a DBOption that sets the name of the explorer database,
+// the searches join its tables, so it must be on the same server and readable by the user.
+func WithExplorerDBName(dbName string) DBOption {
+	return func(o *DBOptions) {
+		o.explorerDBName = dbName
+	}
+}
+
 // WithAutoMigrateTables returns a DBOption that sets the tables to be auto migrated in the database.
 func WithAutoMigrateTables(tables ...interface{}) DBOption {
 	return func(o *DBOptions) {
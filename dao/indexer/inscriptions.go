@@ -3,6 +3,8 @@ package indexer
 import (
 	"database/sql"
 	"errors"
+	"fmt"
+	"github.com/inscription-c/cins/inscription/index"
 	"github.com/inscription-c/explorer-api/constants"
 	"github.com/inscription-c/explorer-api/model"
 	"github.com/inscription-c/explorer-api/tables"
@@ -39,11 +41,46 @@ func (d *DB) GetInscriptionById(inscriptionId *tables.InscriptionId) (ins tables
 	return
 }
 
-// GetInscriptionByOutpoint retrieves an inscription by its outpoint.
-func (d *DB) GetInscriptionByOutpoint(outpoint *model.OutPoint) (list []*tables.InscriptionId, err error) {
-	err = d.Model(&tables.Inscriptions{}).Where("tx_id=? and `index`=?", outpoint.Hash.String(), outpoint.Index).Find(&list).Error
+// GetInscriptionByOutpoint retrieves the inscriptions currently sitting in an outpoint in ascending order of offset.
+// The body and metadata of the inscriptions are omitted.
+func (d *DB) GetInscriptionByOutpoint(outpoint *model.OutPoint) (res []*Inscription, err error) {
+	satPoints := make([]*tables.SatPointToSequenceNum, 0)
+	err = d.Where("outpoint=?", outpoint.String()).Order("offset asc").Find(&satPoints).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		err = nil
+		return
+	}
+	if err != nil || len(satPoints) == 0 {
+		return
+	}
+
+	sequenceNums := make([]int64, 0, len(satPoints))
+	for _, satPoint := range satPoints {
+		sequenceNums = append(sequenceNums, satPoint.SequenceNum)
+	}
+	list := make([]*tables.Inscriptions, 0, len(sequenceNums))
+	err = d.Omit("body", "metadata").Where("sequence_num in (?)", sequenceNums).Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	if err != nil {
+		return
+	}
+
+	insMap := make(map[int64]*tables.Inscriptions, len(list))
+	for _, ins := range list {
+		insMap[ins.SequenceNum] = ins
+	}
+	res = make([]*Inscription, 0, len(satPoints))
+	for _, satPoint := range satPoints {
+		ins, ok := insMap[satPoint.SequenceNum]
+		if !ok {
+			continue
+		}
+		res = append(res, &Inscription{
+			Inscriptions:          ins,
+			SatPointToSequenceNum: satPoint,
+		})
 	}
 	return
 }
@@ -80,28 +117,41 @@ func (d *DB) FindInscriptionsByPage(page, size int) (list []*tables.InscriptionI
 	return
 }
 
-// FindInscriptionsInBlockPage retrieves a page of inscription IDs in a block.
-// It returns a list of inscription IDs and any error encountered.
-func (d *DB) FindInscriptionsInBlockPage(height, page, size int) (list []*model.OutPoint, err error) {
+// blockSequenceRange retrieves the range (from, to] of the sequence numbers inscribed in a block.
+// It returns false if the block is not indexed yet.
+func (d *DB) blockSequenceRange(height uint32) (from, to int64, ok bool, err error) {
 	newBlock := &tables.BlockInfo{}
 	err = d.Where("height=?", height).First(newBlock).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		err = nil
 		return
 	}
+	if err != nil {
+		return
+	}
 
 	oldBlock := &tables.BlockInfo{}
 	if height > 0 {
 		err = d.Where("height=?", height-1).First(oldBlock).Error
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			err = nil
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 			return
 		}
+		err = nil
 	}
+	return oldBlock.SequenceNum, newBlock.SequenceNum, true, nil
+}
 
-	err = d.Model(&tables.Inscriptions{}).
-		Where("sequence_num>? and sequence_num<=?", oldBlock.SequenceNum, newBlock.SequenceNum).
-		Offset((page - 1) * size).Limit(size + 1).Scan(&list).Error
+// FindInscriptionsInBlockPage retrieves a page of inscriptions in a block, the body and metadata are omitted.
+// It returns one more than size entries so that the caller can tell if there are more pages.
+func (d *DB) FindInscriptionsInBlockPage(height uint32, page, size int) (list []*tables.Inscriptions, err error) {
+	from, to, ok, err := d.blockSequenceRange(height)
+	if err != nil || !ok {
+		return
+	}
+
+	err = d.Model(&tables.Inscriptions{}).Omit("body", "metadata").
+		Where("sequence_num>? and sequence_num<=?", from, to).Order("sequence_num asc").
+		Offset((page - 1) * size).Limit(size + 1).Find(&list).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		err = nil
 		return
@@ -109,30 +159,67 @@ func (d *DB) FindInscriptionsInBlockPage(height, page, size int) (list []*model.
 	return
 }
 
-func (d *DB) FindInscriptionsInBlock(height uint32) (list []*model.OutPoint, err error) {
-	newBlock := &tables.BlockInfo{}
-	err = d.Where("height=?", height).First(newBlock).Error
+// FindInscriptionsInBlock retrieves the IDs of all inscriptions in a block.
+// It returns a list of inscription IDs and any error encountered.
+func (d *DB) FindInscriptionsInBlock(height uint32) (list []*tables.InscriptionId, err error) {
+	from, to, ok, err := d.blockSequenceRange(height)
+	if err != nil || !ok {
+		return
+	}
+
+	err = d.Model(&tables.Inscriptions{}).Select("tx_id,offset").
+		Where("sequence_num>? and sequence_num<=?", from, to).Order("sequence_num asc").Find(&list).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		err = nil
 		return
 	}
+	return
+}
 
-	oldBlock := &tables.BlockInfo{}
-	if height > 0 {
-		err = d.Where("height=?", height-1).First(oldBlock).Error
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			err = nil
-			return
-		}
+// FindInscriptionSequencesInBlock retrieves the IDs, sequence numbers and sats of all inscriptions in a block.
+func (d *DB) FindInscriptionSequencesInBlock(height uint32) (list []*tables.Inscriptions, err error) {
+	from, to, ok, err := d.blockSequenceRange(height)
+	if err != nil || !ok {
+		return
 	}
 
-	err = d.Model(&tables.Inscriptions{}).
-		Where("sequence_num>? and sequence_num<=?",
-			oldBlock.SequenceNum, newBlock.SequenceNum).Scan(&list).Error
+	err = d.Select("tx_id", "offset", "sequence_num", "sat").
+		Where("sequence_num>? and sequence_num<=?", from, to).Order("sequence_num asc").Find(&list).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		err = nil
+	}
+	return
+}
+
+// BlockInscriptionsStatistic is the number of inscriptions and the total inscription fees of a block.
+type BlockInscriptionsStatistic struct {
+	Count     int64  `gorm:"column:count"`
+	TotalFees uint64 `gorm:"column:total_fees"`
+}
+
+// InscriptionsStatisticInBlock retrieves the number of inscriptions and the total inscription fees of a block.
+func (d *DB) InscriptionsStatisticInBlock(height uint32) (statistic BlockInscriptionsStatistic, err error) {
+	from, to, ok, err := d.blockSequenceRange(height)
+	if err != nil || !ok {
 		return
 	}
+
+	err = d.Model(&tables.Inscriptions{}).Select("count(*) as count, COALESCE(sum(fee),0) as total_fees").
+		Where("sequence_num>? and sequence_num<=?", from, to).Scan(&statistic).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// FindInscriptionsBySat retrieves a page of inscription IDs on a sat in inscribe order.
+// It returns one more than size entries so that the caller can tell if there are more pages.
+func (d *DB) FindInscriptionsBySat(sat uint64, page, size int) (list []*tables.InscriptionId, err error) {
+	err = d.Model(&tables.Inscriptions{}).Select("tx_id,offset").Where("sat=?", sat).
+		Order("sequence_num asc").Offset((page - 1) * size).Limit(size + 1).Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
 	return
 }
 
@@ -176,6 +263,33 @@ func (d *DB) FirstInscriptionByOwner(owner string) (firts tables.Inscriptions, e
 	return
 }
 
+// LatestInscriptionByOwner retrieves the latest inscription owned by an address.
+func (d *DB) LatestInscriptionByOwner(owner string) (latest tables.Inscriptions, err error) {
+	err = d.Omit("body", "metadata").Where("owner=?", owner).Last(&latest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// MediaTypeStatistic is the number and content size of inscriptions of a media type.
+type MediaTypeStatistic struct {
+	MediaType   string `gorm:"column:media_type" json:"media_type"`
+	Count       int64  `gorm:"column:count" json:"count"`
+	ContentSize uint64 `gorm:"column:content_size" json:"content_size"`
+}
+
+// OwnerInscriptionsStatistic retrieves the inscriptions statistic of an address grouped by media type.
+func (d *DB) OwnerInscriptionsStatistic(owner string) (list []*MediaTypeStatistic, err error) {
+	err = d.Model(&tables.Inscriptions{}).
+		Select("media_type, count(*) as count, sum(content_size) as content_size").
+		Where("owner=?", owner).Group("media_type").Order("count desc").Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
 type FindProtocolsParams struct {
 	Page            int
 	Limit           int
@@ -186,8 +300,22 @@ type FindProtocolsParams struct {
 	ContentTypes    []string
 	Charms          []string
 	InscriptionType string
+	Rarity          string
+	// AfterId is the keyset cursor, when it is set the page is ignored and
+	// the inscriptions after this id in the given order are returned.
+	AfterId uint64
+	// CountTotal counts the total number of matched inscriptions.
+	CountTotal bool
+}
+
+// Filtered reports whether the search has any filter on the inscriptions table.
+func (p *FindProtocolsParams) Filtered() bool {
+	return p.Owner != "" || p.Ticker != "" || p.InscriptionType != "" || p.Rarity != "" ||
+		len(p.MediaTypes) > 0 || len(p.ContentTypes) > 0 || len(p.Charms) > 0
 }
 
+// SearchInscriptions retrieves the inscriptions matched the params.
+// It returns one more than limit entries so that the caller can tell if there are more pages.
 func (d *DB) SearchInscriptions(params *FindProtocolsParams) (list []*tables.Inscriptions, total int64, err error) {
 	db := d.Model(&tables.Inscriptions{})
 	charms := make(map[string]struct{})
@@ -217,19 +345,100 @@ func (d *DB) SearchInscriptions(params *FindProtocolsParams) (list []*tables.Ins
 	if len(params.ContentTypes) > 0 {
 		db = db.Where("inscriptions.content_type in (?)", params.ContentTypes)
 	}
+	if params.Rarity != "" {
+		db, err = d.whereRarity(db, params.Rarity)
+		if err != nil {
+			return
+		}
+	}
+
+	if params.CountTotal {
+		if err = db.Count(&total).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				err = nil
+			}
+			return
+		}
+	}
+
 	switch params.Order {
 	case "newest":
+		if params.AfterId > 0 {
+			db = db.Where("inscriptions.id < ?", params.AfterId)
+		}
 		db = db.Order("inscriptions.id desc")
 	case "oldest":
+		if params.AfterId > 0 {
+			db = db.Where("inscriptions.id > ?", params.AfterId)
+		}
 		db = db.Order("inscriptions.id asc")
 	}
+	if params.AfterId == 0 {
+		db = db.Offset((params.Page - 1) * params.Limit)
+	}
+	err = db.Limit(params.Limit + 1).Find(&list).Error
+	return
+}
 
-	if err = db.Count(&total).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			err = nil
-		}
-		return
+// whereRarity filters the inscriptions on sats of a rarity by the rarities of the inscriptions on uncommon or
+// better sats, which are precomputed in the explorer database, so that no sat is computed in the query.
+// The rarity tables are joined in the same query from the explorer database on the same server.
+// The common inscriptions are only those of the blocks whose rarities are parsed already.
+func (d *DB) whereRarity(db *gorm.DB, rarityName string) (*gorm.DB, error) {
+	rarity, ok := model.StringToRarity(rarityName)
+	if !ok {
+		return nil, fmt.Errorf("unknown rarity %s", rarityName)
+	}
+	if d.opts == nil || d.opts.explorerDBName == "" {
+		return nil, errors.New("explorer database of the rarities is not set")
+	}
+	rarityTable := fmt.Sprintf("`%s`.%s", d.opts.explorerDBName, (&tables.InscriptionRarity{}).TableName())
+	parserTable := fmt.Sprintf("`%s`.%s", d.opts.explorerDBName, (&tables.RarityParserInfo{}).TableName())
+	if rarity != index.RarityCommon {
+		return db.Joins(fmt.Sprintf("JOIN %s AS inscription_rarity ON inscriptions.sequence_num=inscription_rarity.sequence_num", rarityTable)).
+			Where("inscription_rarity.rarity=?", uint8(rarity)), nil
+	}
+	parsed := d.Table(parserTable).Select("coalesce(max(height), -1)")
+	rare := d.Table(rarityTable + " AS inscription_rarity").Select("1").
+		Where("inscription_rarity.sequence_num=inscriptions.sequence_num")
+	return db.Where("inscriptions.height <= (?) and not exists (?)", parsed, rare), nil
+}
+
+// FindInscriptionsOnSat retrieves a page of inscriptions on a sat in inscribe order, the body and metadata are omitted.
+// It returns one more than size entries so that the caller can tell if there are more pages.
+func (d *DB) FindInscriptionsOnSat(sat uint64, page, size int) (list []*tables.Inscriptions, err error) {
+	err = d.Model(&tables.Inscriptions{}).Omit("body", "metadata").
+		Where("sat=?", sat).Order("sequence_num asc").
+		Offset((page - 1) * size).Limit(size + 1).Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// ApproximateInscriptionsNum retrieves the estimated number of inscriptions from the table statistics,
+// it is much cheaper than counting the whole table.
+func (d *DB) ApproximateInscriptionsNum() (total int64, err error) {
+	var totalNull *sql.NullInt64
+	err = d.Table("information_schema.tables").Select("table_rows").
+		Where("table_schema = DATABASE() and table_name = ?", (&tables.Inscriptions{}).TableName()).
+		Scan(&totalNull).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	if totalNull != nil {
+		total = totalNull.Int64
+	}
+	return
+}
+
+// FindInscriptionsAfterSequence retrieves the inscriptions after a sequence number in inscribe order,
+// the body and metadata are omitted.
+func (d *DB) FindInscriptionsAfterSequence(sequenceNum int64, limit int) (list []*tables.Inscriptions, err error) {
+	err = d.Model(&tables.Inscriptions{}).Omit("body", "metadata").
+		Where("sequence_num>?", sequenceNum).Order("sequence_num asc").Limit(limit).Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
 	}
-	err = db.Offset((params.Page - 1) * params.Limit).Limit(params.Limit).Find(&list).Error
 	return
 }
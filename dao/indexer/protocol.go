@@ -0,0 +1,63 @@
+package indexer
+
+import (
+	"errors"
+	"github.com/inscription-c/explorer-api/constants"
+	"github.com/inscription-c/explorer-api/tables"
+	"gorm.io/gorm"
+)
+
+// cbrc20MaxBodySize is the max body size of a c-brc-20 operation, larger inscriptions are not parsed.
+const cbrc20MaxBodySize = 1024
+
+// GetDeployProtocolByTicker retrieves the first c-brc-20 deploy of a ticker, later deploys of the same ticker are ignored.
+func (d *DB) GetDeployProtocolByTicker(ticker string) (p tables.Protocol, err error) {
+	err = d.Where("protocol=? and ticker=? and operator=?", constants.ProtocolCBRC20, ticker, constants.OperationDeploy).
+		Order("sequence_num asc").First(&p).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// FindCBRC20InscriptionsInBlock retrieves the inscriptions in a block whose body may be a c-brc-20 operation.
+func (d *DB) FindCBRC20InscriptionsInBlock(height uint32) (list []*tables.Inscriptions, err error) {
+	from, to, ok, err := d.blockSequenceRange(height)
+	if err != nil || !ok {
+		return
+	}
+
+	err = d.Omit("metadata").Where("sequence_num>? and sequence_num<=?", from, to).
+		Where("content_size<=? and body like ?", cbrc20MaxBodySize, "%"+constants.ProtocolCBRC20+"%").
+		Order("sequence_num asc").Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// FindDeployProtocolsInBlock retrieves the c-brc-20 deploys in a block in inscribe order.
+func (d *DB) FindDeployProtocolsInBlock(height uint32) (list []*tables.Protocol, err error) {
+	from, to, ok, err := d.blockSequenceRange(height)
+	if err != nil || !ok {
+		return
+	}
+
+	err = d.Where("sequence_num>? and sequence_num<=?", from, to).
+		Where("protocol=? and operator=?", constants.ProtocolCBRC20, constants.OperationDeploy).
+		Order("sequence_num asc").Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// FindCBRC20ProtocolsInRange retrieves the c-brc-20 operations of the inscriptions in a sequence number range.
+func (d *DB) FindCBRC20ProtocolsInRange(from, to int64) (list []*tables.Protocol, err error) {
+	err = d.Where("sequence_num>=? and sequence_num<=? and protocol=?", from, to, constants.ProtocolCBRC20).
+		Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
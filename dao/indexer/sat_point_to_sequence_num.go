@@ -0,0 +1,17 @@
+package indexer
+
+import (
+	"errors"
+	"github.com/inscription-c/explorer-api/tables"
+	"gorm.io/gorm"
+)
+
+// GetSatPointBySequenceNum retrieves the current satpoint of an inscription by its sequence number.
+// It returns the satpoint and any error encountered.
+func (d *DB) GetSatPointBySequenceNum(sequenceNum int64) (satPoint tables.SatPointToSequenceNum, err error) {
+	err = d.Where("sequence_num = ?", sequenceNum).Last(&satPoint).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"github.com/inscription-c/explorer-api/tables"
 	"gorm.io/gorm"
+	"time"
 )
 
 func (d *DB) CreateInscribeOrder(order *tables.InscribeOrder) error {
@@ -31,14 +32,34 @@ func (d *DB) FindInscribeOrdersByReceiveAddress(address string, page, limit int)
 	return
 }
 
+// GetInscribeOrdersByRevealAddress retrieves the order waiting for payments at a reveal address.
+// Underpaid orders wait for top-up payments, and expired and refunded orders are included so that late payments can be refunded.
+// Orders with a commit tx in the mempool wait for it, or for the tx that replaced it.
 func (d *DB) GetInscribeOrdersByRevealAddress(address string) (order tables.InscribeOrder, err error) {
-	err = d.Where("reveal_address = ? and status = ?", address, tables.OrderStatusDefault).First(&order).Error
+	err = d.Where("reveal_address = ? and status in ?", address, []tables.OrderStatus{
+		tables.OrderStatusDefault,
+		tables.OrderStatusFeeNotEnough,
+		tables.OrderStatusExpired,
+		tables.OrderStatusCommitSeen,
+		tables.OrderStatusRefunding,
+		tables.OrderStatusRefunded,
+	}).First(&order).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		err = nil
 	}
 	return
 }
 
+// orderSecretColumns are the encrypted columns of an order, they're only changed by reencrypt-keys
+// and are kept out of the full-row saves and the undo logs.
+var orderSecretColumns = []string{"reveal_pri_key", "callback_secret"}
+
+// SaveInscribeOrder saves all the columns of an order but its reveal private key and callback secret, which are
+// only changed by reencrypt-keys, so that a value read before it's re-encrypted is not written back.
+func (d *DB) SaveInscribeOrder(order *tables.InscribeOrder) error {
+	return d.Model(order).Select("*").Omit(orderSecretColumns...).Updates(order).Error
+}
+
 func (d *DB) UpdateInscribeOrderStatus(height uint32, newOrder *tables.InscribeOrder) error {
 	old := &tables.InscribeOrder{}
 	err := d.Where("id = ?", newOrder.Id).First(old).Error
@@ -46,12 +67,199 @@ func (d *DB) UpdateInscribeOrderStatus(height uint32, newOrder *tables.InscribeO
 		return err
 	}
 
-	err = d.Save(newOrder).Error
+	err = d.SaveInscribeOrder(newOrder)
 	if err != nil {
 		return err
 	}
 	sql := d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-		return tx.Save(old)
+		return tx.Model(old).Select("*").Omit(orderSecretColumns...).Updates(old)
 	})
 	return d.AddUndoLog(height, sql)
 }
+
+func (d *DB) CountInscribeOrdersByReceiveAddress(address string) (total int64, err error) {
+	err = d.Model(&tables.InscribeOrder{}).Where("receive_address = ?", address).Count(&total).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// expirableOrderStatuses are the statuses of the orders that are not paid or not fully paid.
+var expirableOrderStatuses = []tables.OrderStatus{
+	tables.OrderStatusDefault,
+	tables.OrderStatusFeeNotEnough,
+}
+
+// FindExpirableInscribeOrders retrieves a batch of the orders created before a time that are not paid or
+// not fully paid, after an id in the order of ids. Only the columns needed to expire an order are read.
+func (d *DB) FindExpirableInscribeOrders(before time.Time, afterId uint64, limit int) (orders []*tables.InscribeOrder, err error) {
+	err = d.Select("id", "order_id", "commit_tx_id", "refund_address").
+		Where("id > ? and status in ? and created_at < ?", afterId, expirableOrderStatuses, before).
+		Order("id asc").Limit(limit).Find(&orders).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// ExpireInscribeOrder marks an order as expired, it does nothing and returns false if the order was paid in the meantime.
+func (d *DB) ExpireInscribeOrder(order *tables.InscribeOrder) (bool, error) {
+	res := d.Model(&tables.InscribeOrder{}).
+		Where("id = ? and status in ?", order.Id, expirableOrderStatuses).
+		Update("status", tables.OrderStatusExpired)
+	return res.RowsAffected > 0, res.Error
+}
+
+// FindRefundableInscribeOrders retrieves the expired orders that have payments to refund.
+func (d *DB) FindRefundableInscribeOrders() (orders []*tables.InscribeOrder, err error) {
+	err = d.Where("status = ? and commit_tx_id != '' and refund_address != ''", tables.OrderStatusExpired).
+		Find(&orders).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// FindInscribeOrdersByStatus retrieves the orders in a status.
+func (d *DB) FindInscribeOrdersByStatus(status tables.OrderStatus) (orders []*tables.InscribeOrder, err error) {
+	err = d.Where("status = ?", status).Find(&orders).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// FindBumpableInscribeOrders retrieves the orders with a sent reveal tx that have a requested bump,
+// or that were sent before a given time.
+func (d *DB) FindBumpableInscribeOrders(sentBefore time.Time) (orders []*tables.InscribeOrder, err error) {
+	err = d.Where("status = ?", tables.OrderStatusRevealSend).
+		Where("bump_fee_rate > fee_rate OR reveal_sent_at < ?", sentBefore).
+		Find(&orders).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// RequestInscribeOrderBump asks the runner to replace the reveal tx of an order with a higher fee rate.
+func (d *DB) RequestInscribeOrderBump(orderId string, feeRate int64) error {
+	return d.Model(&tables.InscribeOrder{}).
+		Where("order_id = ? AND status = ?", orderId, tables.OrderStatusRevealSend).
+		Update("bump_fee_rate", feeRate).Error
+}
+
+// FindMempoolWatchedInscribeOrders retrieves the orders waiting for a commit tx, and the orders with a commit tx in the mempool.
+func (d *DB) FindMempoolWatchedInscribeOrders() (orders []*tables.InscribeOrder, err error) {
+	err = d.Where("status in ?", []tables.OrderStatus{
+		tables.OrderStatusDefault,
+		tables.OrderStatusCommitSeen,
+	}).Find(&orders).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// SetInscribeOrderCommitSeen marks a waiting order as having its commit tx in the mempool,
+// it does nothing and returns false if the order is no longer waiting.
+func (d *DB) SetInscribeOrderCommitSeen(order *tables.InscribeOrder) (bool, error) {
+	res := d.Model(&tables.InscribeOrder{}).
+		Where("id = ? and status = ?", order.Id, tables.OrderStatusDefault).
+		Updates(map[string]interface{}{
+			"status":          tables.OrderStatusCommitSeen,
+			"commit_tx_id":    order.CommitTxId,
+			"commit_tx_index": order.CommitTxIndex,
+			"commit_tx_value": order.CommitTxValue,
+			"reveal_tx_id":    order.RevealTxId,
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+// ResetInscribeOrderCommitSeen moves an order with a commit tx that left the mempool back to waiting,
+// it does nothing and returns false if the block parser found a commit tx in the meantime.
+func (d *DB) ResetInscribeOrderCommitSeen(order *tables.InscribeOrder) (bool, error) {
+	res := d.Model(&tables.InscribeOrder{}).
+		Where("id = ? and status = ? and commit_tx_id = ?", order.Id, tables.OrderStatusCommitSeen, order.CommitTxId).
+		Updates(map[string]interface{}{
+			"status":          tables.OrderStatusDefault,
+			"commit_tx_id":    "",
+			"commit_tx_index": 0,
+			"commit_tx_value": 0,
+			"reveal_tx_id":    "",
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+// FindInscribeOrdersUpdatedSince retrieves the orders updated after the key (since, afterId) in the order of
+// (updated_at, id), the orders updated at since are included from afterId on. Only the columns published by
+// the stream are read.
+func (d *DB) FindInscribeOrdersUpdatedSince(since time.Time, afterId uint64, limit int) (orders []*tables.InscribeOrder, err error) {
+	err = d.Select("id", "order_id", "tx_id", "offset", "status", "receive_address", "commit_tx_id", "reveal_tx_id", "updated_at").
+		Where("(updated_at = ? and id > ?) or updated_at > ?", since, afterId, since).
+		Order("updated_at asc, id asc").Limit(limit).Find(&orders).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// LatestInscribeOrder retrieves the last updated order.
+func (d *DB) LatestInscribeOrder() (order tables.InscribeOrder, err error) {
+	err = d.Order("updated_at desc, id desc").First(&order).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// FindInscribeOrderKeysAfter retrieves the ids, the stored reveal private keys and the callback secrets of the orders
+// after an id in the order of ids, the orders with a derived reveal key and without a callback secret are skipped.
+func (d *DB) FindInscribeOrderKeysAfter(afterId uint64, limit int) (orders []*tables.InscribeOrder, err error) {
+	err = d.Select("id", "order_id", "reveal_pri_key", "callback_secret").
+		Where("id > ? and (reveal_pri_key != '' or callback_secret != '')", afterId).
+		Order("id asc").Limit(limit).Find(&orders).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// UpdateInscribeOrderRevealPriKey replaces the stored reveal private key of an order,
+// it does nothing if the stored key is no longer the one the order was read with.
+func (d *DB) UpdateInscribeOrderRevealPriKey(order *tables.InscribeOrder, revealPriKey string) (bool, error) {
+	res := d.Model(&tables.InscribeOrder{}).
+		Where("id = ? and reveal_pri_key = ?", order.Id, order.RevealPriKey).
+		UpdateColumn("reveal_pri_key", revealPriKey)
+	return res.RowsAffected > 0, res.Error
+}
+
+// UpdateInscribeOrderCallbackSecret replaces the stored callback secret of an order,
+// it does nothing if the stored secret is no longer the one the order was read with.
+func (d *DB) UpdateInscribeOrderCallbackSecret(order *tables.InscribeOrder, callbackSecret string) (bool, error) {
+	res := d.Model(&tables.InscribeOrder{}).
+		Where("id = ? and callback_secret = ?", order.Id, order.CallbackSecret).
+		UpdateColumn("callback_secret", callbackSecret)
+	return res.RowsAffected > 0, res.Error
+}
+
+// CountInscribeOrderKeysWithoutPrefix counts the orders with a stored reveal private key or callback secret
+// that doesn't start with a prefix.
+func (d *DB) CountInscribeOrderKeysWithoutPrefix(prefix string) (total int64, err error) {
+	err = d.Model(&tables.InscribeOrder{}).
+		Where("(reveal_pri_key != '' and reveal_pri_key not like ?) or (callback_secret != '' and callback_secret not like ?)",
+			prefix+"%", prefix+"%").Count(&total).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// GetInscribeOrderByRevealKeyPath retrieves the order with a reveal key derived by a path.
+func (d *DB) GetInscribeOrderByRevealKeyPath(path string) (order tables.InscribeOrder, err error) {
+	err = d.Where("reveal_key_path = ?", path).First(&order).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
@@ -0,0 +1,66 @@
+package dao
+
+import (
+	"errors"
+	"github.com/inscription-c/explorer-api/tables"
+	"gorm.io/gorm"
+)
+
+// CreateInscribeOrderEvent records an event of an order that doesn't belong to a parsed block,
+// the event is queued for the callback url of the order.
+func (d *DB) CreateInscribeOrderEvent(event *tables.InscribeOrderEvent) error {
+	if err := d.Create(event).Error; err != nil {
+		return err
+	}
+	_, err := d.enqueueWebhookDelivery(event)
+	return err
+}
+
+// CreateInscribeOrderBlockEvent records an event of an order found in a parsed block, the event is queued for
+// the callback url of the order. The event and its delivery, unless it's already sent, are deleted if its block is reorged.
+func (d *DB) CreateInscribeOrderBlockEvent(event *tables.InscribeOrderEvent) error {
+	if err := d.Create(event).Error; err != nil {
+		return err
+	}
+	sql := d.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Delete(event)
+	})
+	if err := d.AddUndoLog(event.Height, sql); err != nil {
+		return err
+	}
+	delivery, err := d.enqueueWebhookDelivery(event)
+	if err != nil || delivery == nil {
+		return err
+	}
+	sql = d.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("status = ?", tables.WebhookDeliveryStatusPending).Delete(delivery)
+	})
+	return d.AddUndoLog(event.Height, sql)
+}
+
+// GetInscribeOrderEventById retrieves an event by its id.
+func (d *DB) GetInscribeOrderEventById(id uint64) (event tables.InscribeOrderEvent, err error) {
+	err = d.Where("id = ?", id).First(&event).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// LastInscribeOrderEvent retrieves the latest event of an order.
+func (d *DB) LastInscribeOrderEvent(orderId string) (event tables.InscribeOrderEvent, err error) {
+	err = d.Where("order_id = ?", orderId).Order("id desc").First(&event).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// FindInscribeOrderEvents retrieves the events of an order in the order they happened.
+func (d *DB) FindInscribeOrderEvents(orderId string) (list []*tables.InscribeOrderEvent, err error) {
+	err = d.Where("order_id = ?", orderId).Order("id asc").Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
@@ -0,0 +1,59 @@
+package dao
+
+import (
+	"errors"
+	"github.com/inscription-c/explorer-api/tables"
+	"gorm.io/gorm"
+)
+
+// CreateBatchInscribeOrder creates an inscribe order with its inscriptions.
+func (d *DB) CreateBatchInscribeOrder(order *tables.InscribeOrder, items []*tables.InscribeOrderItem) error {
+	if err := d.Create(order).Error; err != nil {
+		return err
+	}
+	return d.Create(items).Error
+}
+
+// FindInscribeOrderItems retrieves the inscriptions of a batch inscribe order by index.
+func (d *DB) FindInscribeOrderItems(orderId string) (list []*tables.InscribeOrderItem, err error) {
+	err = d.Where("order_id = ?", orderId).Order("`index` asc").Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// UpdateInscribeOrderItemsInscriptionId sets the inscription ids of a batch inscribe order,
+// the envelope at each index of the reveal transaction is the inscription with the same offset.
+func (d *DB) UpdateInscribeOrderItemsInscriptionId(orderId, revealTxId string) error {
+	return d.Model(&tables.InscribeOrderItem{}).Where("order_id = ?", orderId).
+		Updates(map[string]interface{}{
+			"tx_id":  revealTxId,
+			"offset": gorm.Expr("`index`"),
+		}).Error
+}
+
+// UpdateInscribeOrderBlockItemsInscriptionId sets the inscription ids of a batch inscribe order found
+// inscribed in a parsed block, the previous ids are restored if the block is reorged.
+func (d *DB) UpdateInscribeOrderBlockItemsInscriptionId(height uint32, orderId, revealTxId string) error {
+	items, err := d.FindInscribeOrderItems(orderId)
+	if err != nil {
+		return err
+	}
+	if err := d.UpdateInscribeOrderItemsInscriptionId(orderId, revealTxId); err != nil {
+		return err
+	}
+	for _, item := range items {
+		sql := d.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return tx.Model(&tables.InscribeOrderItem{}).Where("id = ?", item.Id).
+				Updates(map[string]interface{}{
+					"tx_id":  item.TxId,
+					"offset": item.Offset,
+				})
+		})
+		if err := d.AddUndoLog(height, sql); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package dao
+
+import (
+	"errors"
+	"github.com/inscription-c/explorer-api/tables"
+	"gorm.io/gorm"
+)
+
+// CreateInscribeOrderPayment records a payment of an order, the payment is deleted if its block is reorged.
+func (d *DB) CreateInscribeOrderPayment(payment *tables.InscribeOrderPayment) error {
+	if err := d.Create(payment).Error; err != nil {
+		return err
+	}
+	sql := d.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Delete(payment)
+	})
+	return d.AddUndoLog(payment.Height, sql)
+}
+
+// FindInscribeOrderPayments retrieves the payments of an order in the order they are found.
+func (d *DB) FindInscribeOrderPayments(orderId string) (list []*tables.InscribeOrderPayment, err error) {
+	err = d.Where("order_id = ?", orderId).Order("id asc").Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
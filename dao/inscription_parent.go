@@ -0,0 +1,61 @@
+package dao
+
+import (
+	"errors"
+	"github.com/inscription-c/explorer-api/tables"
+	"gorm.io/gorm"
+)
+
+// LastParentParserInfo retrieves the last block parsed for parent tags.
+func (d *DB) LastParentParserInfo() (info tables.ParentParserInfo, err error) {
+	err = d.Order("height desc").First(&info).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// FindParentParserInfos retrieves the kept parsed blocks in descending order of height.
+func (d *DB) FindParentParserInfos() (list []*tables.ParentParserInfo, err error) {
+	err = d.Order("height desc").Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// CreateParentBlock saves the parents of the inscriptions of a parsed block and the block itself,
+// only the latest keep blocks are kept for reorg detection.
+func (d *DB) CreateParentBlock(info *tables.ParentParserInfo, parents []*tables.InscriptionParent, keep uint32) error {
+	if len(parents) > 0 {
+		if err := d.Create(&parents).Error; err != nil {
+			return err
+		}
+	}
+	if err := d.Create(info).Error; err != nil {
+		return err
+	}
+	if info.Height < keep {
+		return nil
+	}
+	return d.Where("height < ?", info.Height-keep).Delete(&tables.ParentParserInfo{}).Error
+}
+
+// DeleteParentsFrom deletes the parents and parsed blocks from a height, they are parsed again after a reorg.
+func (d *DB) DeleteParentsFrom(height uint32) error {
+	if err := d.Where("height >= ?", height).Delete(&tables.InscriptionParent{}).Error; err != nil {
+		return err
+	}
+	return d.Where("height >= ?", height).Delete(&tables.ParentParserInfo{}).Error
+}
+
+// FindInscriptionChildren retrieves a page of the children of an inscription in inscribe order.
+// It returns one more than size entries so that the caller can tell if there are more pages.
+func (d *DB) FindInscriptionChildren(parentId string, page, size int) (list []*tables.InscriptionParent, err error) {
+	err = d.Where("parent_id = ?", parentId).Order("sequence_num asc").
+		Offset((page - 1) * size).Limit(size + 1).Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
@@ -0,0 +1,50 @@
+package dao
+
+import (
+	"errors"
+	"github.com/inscription-c/explorer-api/tables"
+	"gorm.io/gorm"
+)
+
+// LastRarityParserInfo retrieves the last block parsed for rarities.
+func (d *DB) LastRarityParserInfo() (info tables.RarityParserInfo, err error) {
+	err = d.Order("height desc").First(&info).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// FindRarityParserInfos retrieves the kept parsed blocks in descending order of height.
+func (d *DB) FindRarityParserInfos() (list []*tables.RarityParserInfo, err error) {
+	err = d.Order("height desc").Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// CreateRarityBlock saves the rarities of the inscriptions of a parsed block and the block itself,
+// only the latest keep blocks are kept for reorg detection.
+func (d *DB) CreateRarityBlock(info *tables.RarityParserInfo, rarities []*tables.InscriptionRarity, keep uint32) error {
+	if len(rarities) > 0 {
+		if err := d.Create(&rarities).Error; err != nil {
+			return err
+		}
+	}
+	if err := d.Create(info).Error; err != nil {
+		return err
+	}
+	if info.Height < keep {
+		return nil
+	}
+	return d.Where("height < ?", info.Height-keep).Delete(&tables.RarityParserInfo{}).Error
+}
+
+// DeleteRaritiesFrom deletes the rarities and parsed blocks from a height, they are parsed again after a reorg.
+func (d *DB) DeleteRaritiesFrom(height uint32) error {
+	if err := d.Where("height >= ?", height).Delete(&tables.InscriptionRarity{}).Error; err != nil {
+		return err
+	}
+	return d.Where("height >= ?", height).Delete(&tables.RarityParserInfo{}).Error
+}
@@ -42,6 +42,17 @@ func (d *DB) FindUndoLog() (*sql.Rows, error) {
 	return d.Model(&tables.UndoLog{}).Order("id desc").Rows()
 }
 
+// FindUndoLogsContaining retrieves the undo logs whose sql statement contains a string.
+func (d *DB) FindUndoLogsContaining(s string) (list []*tables.UndoLog, err error) {
+	err = d.Where("`sql` like ?", "%"+s+"%").Order("id").Find(&list).Error
+	return
+}
+
+// UpdateUndoLogSql updates the sql statement of an undo log.
+func (d *DB) UpdateUndoLogSql(id uint64, sql string) error {
+	return d.Model(&tables.UndoLog{}).Where("id = ?", id).Update("sql", sql).Error
+}
+
 // DeleteUndoLog is a method that deletes all undo logs from the database.
 // It returns an error.
 // The method creates a new UndoLog struct and uses its TableName method to get the name of the table.
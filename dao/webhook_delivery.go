@@ -0,0 +1,67 @@
+package dao
+
+import (
+	"errors"
+	"github.com/inscription-c/explorer-api/tables"
+	"gorm.io/gorm"
+	"time"
+)
+
+// enqueueWebhookDelivery queues the notification of an event if the order of the event has a callback url.
+func (d *DB) enqueueWebhookDelivery(event *tables.InscribeOrderEvent) (*tables.WebhookDelivery, error) {
+	order := &tables.InscribeOrder{}
+	err := d.Select("callback_url").Where("order_id = ?", event.OrderId).First(order).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil || order.CallbackUrl == "" {
+		return nil, err
+	}
+	delivery := &tables.WebhookDelivery{
+		OrderId: event.OrderId,
+		EventId: event.Id,
+		Url:     order.CallbackUrl,
+	}
+	return delivery, d.Create(delivery).Error
+}
+
+// FindDueWebhookDeliveries retrieves the pending deliveries whose next attempt is due.
+func (d *DB) FindDueWebhookDeliveries(now time.Time, limit int) (list []*tables.WebhookDelivery, err error) {
+	err = d.Where("status = ? and next_attempt_at <= ?", tables.WebhookDeliveryStatusPending, now).
+		Order("next_attempt_at asc, id asc").Limit(limit).Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// FindFailedWebhookDeliveries retrieves a page of the deliveries that failed all attempts, the latest first.
+// It returns one more than size entries so that the caller can tell if there are more pages.
+func (d *DB) FindFailedWebhookDeliveries(page, size int) (list []*tables.WebhookDelivery, err error) {
+	err = d.Where("status = ?", tables.WebhookDeliveryStatusFailed).
+		Order("id desc").Offset((page - 1) * size).Limit(size + 1).Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// GetWebhookDeliveryById retrieves a delivery by its id.
+func (d *DB) GetWebhookDeliveryById(id uint64) (delivery tables.WebhookDelivery, err error) {
+	err = d.Where("id = ?", id).First(&delivery).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// ReplayWebhookDelivery queues a failed delivery again with a new set of attempts.
+func (d *DB) ReplayWebhookDelivery(id uint64) error {
+	return d.Model(&tables.WebhookDelivery{}).
+		Where("id = ? and status = ?", id, tables.WebhookDeliveryStatusFailed).
+		Updates(map[string]interface{}{
+			"status":          tables.WebhookDeliveryStatusPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		}).Error
+}
@@ -0,0 +1,89 @@
+package handle
+
+import (
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/cins/pkg/util"
+	"github.com/inscription-c/explorer-api/dao/indexer"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/tables"
+	"golang.org/x/sync/errgroup"
+	"net/http"
+	"strings"
+)
+
+type AddressResp struct {
+	Address           string                        `json:"address"`
+	InscriptionCount  int64                         `json:"inscription_count"`
+	TotalContentBytes uint64                        `json:"total_content_bytes"`
+	MediaTypes        []*indexer.MediaTypeStatistic `json:"media_types"`
+	CBRC20            []*tables.ProtocolAmount      `json:"cbrc20"`
+	FirstInscription  *InscriptionEntry             `json:"first_inscription"`
+	LatestInscription *InscriptionEntry             `json:"latest_inscription"`
+	OrderCount        int64                         `json:"order_count"`
+}
+
+// Address return the portfolio of an address, including the inscriptions statistic,
+// the c-brc-20 holdings and the number of inscribe orders.
+func (h *Handler) Address(ctx *gin.Context) {
+	address := strings.TrimSpace(ctx.Param("address"))
+	addr, err := btcutil.DecodeAddress(address, util.ActiveNet.Params)
+	if err != nil || !addr.IsForNet(util.ActiveNet.Params) {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "address is invalid"))
+		return
+	}
+	if err := h.doAddress(ctx, addr.EncodeAddress()); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doAddress(ctx *gin.Context, address string) error {
+	resp := &AddressResp{
+		Address: address,
+	}
+
+	errWg := &errgroup.Group{}
+	errWg.Go(func() (err error) {
+		resp.MediaTypes, err = h.IndexerDB().OwnerInscriptionsStatistic(address)
+		return
+	})
+	errWg.Go(func() (err error) {
+		resp.CBRC20, err = h.DB().SumCBRC20AmountByOwner(address)
+		return
+	})
+	errWg.Go(func() error {
+		first, err := h.IndexerDB().FirstInscriptionByOwner(address)
+		if err != nil {
+			return err
+		}
+		if first.Id > 0 {
+			resp.FirstInscription = insToScanEntry(&first)
+		}
+		return nil
+	})
+	errWg.Go(func() error {
+		latest, err := h.IndexerDB().LatestInscriptionByOwner(address)
+		if err != nil {
+			return err
+		}
+		if latest.Id > 0 {
+			resp.LatestInscription = insToScanEntry(&latest)
+		}
+		return nil
+	})
+	errWg.Go(func() (err error) {
+		resp.OrderCount, err = h.DB().CountInscribeOrdersByReceiveAddress(address)
+		return
+	})
+	if err := errWg.Wait(); err != nil {
+		return err
+	}
+
+	for _, v := range resp.MediaTypes {
+		resp.InscriptionCount += v.Count
+		resp.TotalContentBytes += v.ContentSize
+	}
+	ctx.JSON(http.StatusOK, resp)
+	return nil
+}
@@ -24,4 +24,5 @@ func NewResponse(code ApiCode, message string) *Response {
 const (
 	InternalServerErr ApiCode = 500
 	InvalidParams     ApiCode = 10000
+	InvalidMetadata   ApiCode = 10001
 )
@@ -0,0 +1,186 @@
+package handle
+
+import (
+	"errors"
+	"fmt"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/model"
+	"github.com/inscription-c/explorer-api/tables"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type BlockResp struct {
+	Height            uint32 `json:"height"`
+	Hash              string `json:"hash"`
+	PreviousBlockHash string `json:"previous_block_hash"`
+	MerkleRoot        string `json:"merkle_root"`
+	Version           int32  `json:"version"`
+	Bits              string `json:"bits"`
+	Nonce             uint32 `json:"nonce"`
+	Timestamp         int64  `json:"timestamp"`
+	InscriptionCount  int64  `json:"inscription_count"`
+	TotalFees         uint64 `json:"total_fees"`
+}
+
+type BlockPageReq struct {
+	Page  int `form:"page" binding:"omitempty,min=1"`
+	Limit int `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+func (req *BlockPageReq) Check() error {
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 50
+	}
+	return nil
+}
+
+type BlockInscriptionsResp struct {
+	model.PageResponse
+	List []*InscriptionEntry `json:"list"`
+}
+
+// Block return the decoded header and inscription statistics of a block by height or hash
+func (h *Handler) Block(ctx *gin.Context) {
+	if err := h.doBlock(ctx, ctx.Param("height_or_hash")); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doBlock(ctx *gin.Context, heightOrHash string) error {
+	block, ok, err := h.findBlock(ctx, heightOrHash)
+	if err != nil || !ok {
+		return err
+	}
+	header, err := block.LoadHeader()
+	if err != nil {
+		return err
+	}
+	statistic, err := h.IndexerDB().InscriptionsStatisticInBlock(block.Height)
+	if err != nil {
+		return err
+	}
+
+	ctx.JSON(http.StatusOK, &BlockResp{
+		Height:            block.Height,
+		Hash:              header.BlockHash().String(),
+		PreviousBlockHash: header.PrevBlock.String(),
+		MerkleRoot:        header.MerkleRoot.String(),
+		Version:           header.Version,
+		Bits:              fmt.Sprintf("%08x", header.Bits),
+		Nonce:             header.Nonce,
+		Timestamp:         header.Timestamp.Unix(),
+		InscriptionCount:  statistic.Count,
+		TotalFees:         statistic.TotalFees,
+	})
+	return nil
+}
+
+// BlockInscriptions return a page of inscriptions in a block
+func (h *Handler) BlockInscriptions(ctx *gin.Context) {
+	req := &BlockPageReq{}
+	if err := ctx.ShouldBindQuery(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, err.Error()))
+		return
+	}
+	if err := req.Check(); err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, err.Error()))
+		return
+	}
+	if err := h.doBlockInscriptions(ctx, ctx.Param("height_or_hash"), req); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doBlockInscriptions(ctx *gin.Context, heightOrHash string, req *BlockPageReq) error {
+	block, ok, err := h.findBlock(ctx, heightOrHash)
+	if err != nil || !ok {
+		return err
+	}
+	list, err := h.IndexerDB().FindInscriptionsInBlockPage(block.Height, req.Page, req.Limit)
+	if err != nil {
+		return err
+	}
+
+	resp := &BlockInscriptionsResp{
+		PageResponse: model.PageResponse{
+			PageIndex: req.Page,
+			More:      len(list) > req.Limit,
+		},
+		List: make([]*InscriptionEntry, 0, len(list)),
+	}
+	if resp.More {
+		list = list[:req.Limit]
+	}
+	for _, ins := range list {
+		resp.List = append(resp.List, insToScanEntry(ins))
+	}
+	ctx.JSON(http.StatusOK, resp)
+	return nil
+}
+
+// findBlock resolves a block height or block hash to an indexed block.
+// It writes the bad request or not found response itself and returns false in that case.
+func (h *Handler) findBlock(ctx *gin.Context, heightOrHash string) (block tables.BlockInfo, ok bool, err error) {
+	heightOrHash = strings.TrimSpace(heightOrHash)
+	var height uint64
+	var hash *chainhash.Hash
+	if len(heightOrHash) == chainhash.MaxHashStringSize {
+		var hashErr error
+		hash, hashErr = chainhash.NewHashFromStr(heightOrHash)
+		if hashErr != nil {
+			ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "block hash is invalid"))
+			return
+		}
+		header, rpcErr := h.RpcClient().GetBlockHeaderVerbose(hash)
+		var jsonErr *btcjson.RPCError
+		if errors.As(rpcErr, &jsonErr) && jsonErr.Code == btcjson.ErrRPCBlockNotFound {
+			ctx.Status(http.StatusNotFound)
+			return
+		}
+		if rpcErr != nil {
+			err = rpcErr
+			return
+		}
+		height = uint64(header.Height)
+	} else {
+		var parseErr error
+		height, parseErr = strconv.ParseUint(heightOrHash, 10, 32)
+		if parseErr != nil {
+			ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "height is invalid"))
+			return
+		}
+	}
+
+	block, err = h.IndexerDB().GetBlockInfoByHeight(uint32(height))
+	if err != nil {
+		return
+	}
+	if block.Id == 0 {
+		ctx.Status(http.StatusNotFound)
+		return
+	}
+	if hash != nil {
+		// the block may be reorged out of the indexer
+		header, loadErr := block.LoadHeader()
+		if loadErr != nil {
+			err = loadErr
+			return
+		}
+		if header.BlockHash() != *hash {
+			ctx.Status(http.StatusNotFound)
+			return
+		}
+	}
+	ok = true
+	return
+}
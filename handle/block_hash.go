@@ -0,0 +1,45 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"net/http"
+	"strconv"
+)
+
+// BlockHash return latest block hash, or return block hash by height.
+func (h *Handler) BlockHash(ctx *gin.Context) {
+	if err := h.doBlockHash(ctx, ctx.Param("height")); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doBlockHash(ctx *gin.Context, heightStr string) error {
+	block, err := h.IndexerDB().LatestBlockInfo()
+	if err != nil {
+		return err
+	}
+	if heightStr != "" {
+		height, err := strconv.ParseUint(heightStr, 10, 32)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "height is invalid"))
+			return nil
+		}
+		block, err = h.IndexerDB().GetBlockInfoByHeight(uint32(height))
+		if err != nil {
+			return err
+		}
+	}
+	if block.Id == 0 {
+		ctx.Status(http.StatusNotFound)
+		return nil
+	}
+
+	header, err := block.LoadHeader()
+	if err != nil {
+		return err
+	}
+	ctx.String(http.StatusOK, header.BlockHash().String())
+	return nil
+}
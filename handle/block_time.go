@@ -0,0 +1,29 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"net/http"
+)
+
+// BlockTime return the unix timestamp of the latest block
+func (h *Handler) BlockTime(ctx *gin.Context) {
+	if err := h.doBlockTime(ctx); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doBlockTime(ctx *gin.Context) error {
+	block, err := h.IndexerDB().LatestBlockInfo()
+	if err != nil {
+		return err
+	}
+	if block.Id == 0 {
+		ctx.Status(http.StatusNotFound)
+		return nil
+	}
+	ctx.String(http.StatusOK, gconv.String(block.Timestamp))
+	return nil
+}
@@ -0,0 +1,83 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/model"
+	"github.com/inscription-c/explorer-api/tables"
+	"net/http"
+)
+
+type BlocksResp struct {
+	model.PageResponse
+	List []*BlockEntry `json:"list"`
+}
+
+type BlockEntry struct {
+	Height           uint32 `json:"height"`
+	Hash             string `json:"hash"`
+	Timestamp        int64  `json:"timestamp"`
+	InscriptionCount int64  `json:"inscription_count"`
+}
+
+// Blocks return a page of the most recent blocks with their inscription numbers
+func (h *Handler) Blocks(ctx *gin.Context) {
+	req := &BlockPageReq{}
+	if err := ctx.ShouldBindQuery(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, err.Error()))
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = 10
+	}
+	if err := req.Check(); err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, err.Error()))
+		return
+	}
+	if err := h.doBlocks(ctx, req); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doBlocks(ctx *gin.Context, req *BlockPageReq) error {
+	list, err := h.IndexerDB().FindLatestBlockInfos(req.Page, req.Limit)
+	if err != nil {
+		return err
+	}
+	resp := &BlocksResp{
+		PageResponse: model.PageResponse{
+			PageIndex: req.Page,
+			More:      len(list) > req.Limit,
+		},
+		List: make([]*BlockEntry, 0, len(list)),
+	}
+
+	// the sequence number of a block is the last sequence number inscribed in or before it,
+	// so the inscriptions number of a block is the difference to its previous block.
+	if len(list) > 0 && !resp.More {
+		previous := &tables.BlockInfo{}
+		if last := list[len(list)-1]; last.Height > 0 {
+			*previous, err = h.IndexerDB().GetBlockInfoByHeight(last.Height - 1)
+			if err != nil {
+				return err
+			}
+		}
+		list = append(list, previous)
+	}
+	for i := 0; i < len(list)-1; i++ {
+		block := list[i]
+		header, err := block.LoadHeader()
+		if err != nil {
+			return err
+		}
+		resp.List = append(resp.List, &BlockEntry{
+			Height:           block.Height,
+			Hash:             header.BlockHash().String(),
+			Timestamp:        header.Timestamp.Unix(),
+			InscriptionCount: block.SequenceNum - list[i+1].SequenceNum,
+		})
+	}
+	ctx.JSON(http.StatusOK, resp)
+	return nil
+}
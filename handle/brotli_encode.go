@@ -0,0 +1,31 @@
+//go:build !purebrotli
+
+package handle
+
+// #cgo LDFLAGS: -lbrotlienc
+// #include <brotli/encode.h>
+import "C"
+
+import (
+	"errors"
+	"github.com/inscription-c/explorer-api/constants"
+	"unsafe"
+)
+
+// encodeBrotli compresses a body with libbrotlienc in the mode of the media.
+func encodeBrotli(body []byte, mode constants.BrotliMode) ([]byte, error) {
+	encoded := make([]byte, C.BrotliEncoderMaxCompressedSize(C.size_t(len(body))))
+	if len(encoded) == 0 {
+		return nil, errors.New("brotli body is too large")
+	}
+	var input *C.uint8_t
+	if len(body) > 0 {
+		input = (*C.uint8_t)(unsafe.Pointer(&body[0]))
+	}
+	encodedSize := C.size_t(len(encoded))
+	if C.BrotliEncoderCompress(C.int(brotliQuality), C.int(brotliLGWin), C.BrotliEncoderMode(mode),
+		C.size_t(len(body)), input, &encodedSize, (*C.uint8_t)(unsafe.Pointer(&encoded[0]))) == C.BROTLI_FALSE {
+		return nil, errors.New("brotli compression failed")
+	}
+	return encoded[:encodedSize], nil
+}
@@ -0,0 +1,32 @@
+//go:build !purebrotli
+
+package handle
+
+import (
+	"bytes"
+	"github.com/inscription-c/explorer-api/constants"
+	"math/rand"
+	"testing"
+)
+
+// TestEncodeBrotliMode checks the mode reaches libbrotlienc, the font mode changes the distance
+// parameters of the stream, so a body with matches at many distances is encoded differently.
+func TestEncodeBrotliMode(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	words := []string{"alpha ", "beta ", "gamma ", "delta\n", "<glyph>", "0x3f2a", "kern "}
+	body := bytes.NewBuffer(nil)
+	for i := 0; i < 3000; i++ {
+		body.WriteString(words[r.Intn(len(words))])
+	}
+	generic, err := encodeBrotli(body.Bytes(), constants.BrotliModeGeneric)
+	if err != nil {
+		t.Fatalf("encodeBrotli(generic) error = %v", err)
+	}
+	font, err := encodeBrotli(body.Bytes(), constants.BrotliModeFont)
+	if err != nil {
+		t.Fatalf("encodeBrotli(font) error = %v", err)
+	}
+	if bytes.Equal(generic, font) {
+		t.Error("encodeBrotli() ignores the mode, the generic and font encodings are equal")
+	}
+}
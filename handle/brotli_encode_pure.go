@@ -0,0 +1,23 @@
+//go:build purebrotli
+
+package handle
+
+import (
+	"bytes"
+	"github.com/andybalholm/brotli"
+	"github.com/inscription-c/explorer-api/constants"
+)
+
+// encodeBrotli compresses a body with the Go brotli encoder, it's only used by the purebrotli builds.
+// It has no mode parameter and ignores the mode of the media.
+func encodeBrotli(body []byte, _ constants.BrotliMode) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	bw := brotli.NewWriterOptions(buf, brotli.WriterOptions{Quality: brotliQuality, LGWin: brotliLGWin})
+	if _, err := bw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := bw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,36 @@
+package handle
+
+import (
+	"bytes"
+	"github.com/inscription-c/explorer-api/constants"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestCompressBrotli(t *testing.T) {
+	random := make([]byte, 4096)
+	rand.New(rand.NewSource(1)).Read(random)
+	bodies := map[string][]byte{
+		"empty":  {},
+		"text":   []byte(strings.Repeat("<svg xmlns=\"http://www.w3.org/2000/svg\"><rect width=\"1\"/></svg>\n", 64)),
+		"random": random,
+	}
+	for _, media := range constants.Medias {
+		for name, body := range bodies {
+			t.Run(string(media.ContentType)+" "+name, func(t *testing.T) {
+				compressed, err := compressBrotli(body, media.BrotliMode)
+				if err != nil {
+					t.Fatalf("compressBrotli() error = %v", err)
+				}
+				decompressed, err := decodeBrotli(compressed)
+				if err != nil {
+					t.Fatalf("decodeBrotli() error = %v", err)
+				}
+				if !bytes.Equal(decompressed, body) {
+					t.Errorf("decodeBrotli() = %d bytes, want the %d bytes of the body", len(decompressed), len(body))
+				}
+			})
+		}
+	}
+}
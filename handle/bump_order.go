@@ -0,0 +1,74 @@
+package handle
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/explorer-api/config"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/tables"
+	"net/http"
+)
+
+type BumpOrderReq struct {
+	FeeRate int64 `json:"fee_rate" binding:"gt=0"`
+}
+
+type BumpOrderResp struct {
+	OrderId     string `json:"order_id"`
+	RevealTxId  string `json:"reveal_tx_id"`
+	FeeRate     int64  `json:"fee_rate"`
+	BumpFeeRate int64  `json:"bump_fee_rate"`
+}
+
+// BumpOrder requests the replacement of the unconfirmed reveal tx of an order with a higher fee rate,
+// the runner sends the replacement. It's only served to the admin accounts.
+func (h *Handler) BumpOrder(ctx *gin.Context) {
+	orderId := ctx.Param("order_id")
+	if orderId == "" {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "order_id is required"))
+		return
+	}
+	req := &BumpOrderReq{}
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, err.Error()))
+		return
+	}
+	if err := h.doBumpOrder(ctx, orderId, req); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doBumpOrder(ctx *gin.Context, orderId string, req *BumpOrderReq) error {
+	order, err := h.DB().GetInscribeOrderByOrderId(orderId)
+	if err != nil {
+		return err
+	}
+	if order.Id == 0 {
+		ctx.Status(http.StatusNotFound)
+		return nil
+	}
+	if order.Status != tables.OrderStatusRevealSend {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "reveal tx is not waiting for confirmation"))
+		return nil
+	}
+	if maxFeeRate := config.Cfg.Order.MaxFeeRate; maxFeeRate > 0 && req.FeeRate > maxFeeRate {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, fmt.Sprintf("fee_rate must not be higher than %d", maxFeeRate)))
+		return nil
+	}
+	if req.FeeRate <= order.FeeRate {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "fee_rate must be higher than the fee rate of the reveal tx"))
+		return nil
+	}
+
+	if err := h.DB().RequestInscribeOrderBump(orderId, req.FeeRate); err != nil {
+		return err
+	}
+	ctx.JSON(http.StatusOK, &BumpOrderResp{
+		OrderId:     order.OrderId,
+		RevealTxId:  order.RevealTxId,
+		FeeRate:     order.FeeRate,
+		BumpFeeRate: req.FeeRate,
+	})
+	return nil
+}
@@ -0,0 +1,51 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/explorer-api/constants"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"net/http"
+	"strings"
+)
+
+type CBRC20TokenResp struct {
+	*CBRC20TokenEntry
+	DeployInscription *InscriptionEntry `json:"deploy_inscription"`
+}
+
+// CBRC20Token return the detail and mint progress of a c-brc-20 token
+func (h *Handler) CBRC20Token(ctx *gin.Context) {
+	ticker := strings.TrimSpace(ctx.Param("ticker"))
+	if !constants.TickNameRegexp.MatchString(ticker) {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "ticker is invalid"))
+		return
+	}
+	if err := h.doCBRC20Token(ctx, ticker); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doCBRC20Token(ctx *gin.Context, ticker string) error {
+	token, err := h.DB().GetCBRC20TokenByTicker(ticker)
+	if err != nil {
+		return err
+	}
+	if token.Id == 0 {
+		ctx.Status(http.StatusNotFound)
+		return nil
+	}
+
+	resp := &CBRC20TokenResp{
+		CBRC20TokenEntry: newCBRC20TokenEntry(&token),
+	}
+	deploy, err := h.IndexerDB().GetInscriptionBySequenceNum(token.SequenceNum)
+	if err != nil {
+		return err
+	}
+	if deploy.Id > 0 {
+		resp.DeployInscription = insToScanEntry(&deploy)
+	}
+	ctx.JSON(http.StatusOK, resp)
+	return nil
+}
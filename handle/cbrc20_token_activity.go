@@ -0,0 +1,110 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/explorer-api/constants"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/model"
+	"github.com/inscription-c/explorer-api/tables"
+	"net/http"
+	"strings"
+)
+
+type CBRC20TokenActivityResp struct {
+	model.PageResponse
+	Ticker   string                 `json:"ticker"`
+	TickerId string                 `json:"ticker_id"`
+	List     []*CBRC20ActivityEntry `json:"list"`
+}
+
+type CBRC20ActivityEntry struct {
+	Operation     string `json:"operation"`
+	InscriptionId string `json:"inscription_id"`
+	Owner         string `json:"owner"`
+	Amount        string `json:"amount"`
+	Height        uint32 `json:"height"`
+	Timestamp     int64  `json:"timestamp"`
+}
+
+// CBRC20TokenActivity return a page of the deploy and mint operations of a c-brc-20 token in inscribe order.
+// The deploy is always the first operation, its amount is the max supply.
+func (h *Handler) CBRC20TokenActivity(ctx *gin.Context) {
+	ticker := strings.TrimSpace(ctx.Param("ticker"))
+	if !constants.TickNameRegexp.MatchString(ticker) {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "ticker is invalid"))
+		return
+	}
+	req := &BlockPageReq{}
+	if err := ctx.ShouldBindQuery(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, err.Error()))
+		return
+	}
+	if err := req.Check(); err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, err.Error()))
+		return
+	}
+	if err := h.doCBRC20TokenActivity(ctx, ticker, req); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doCBRC20TokenActivity(ctx *gin.Context, ticker string, req *BlockPageReq) error {
+	token, err := h.DB().GetCBRC20TokenByTicker(ticker)
+	if err != nil {
+		return err
+	}
+	if token.Id == 0 {
+		ctx.Status(http.StatusNotFound)
+		return nil
+	}
+
+	resp := &CBRC20TokenActivityResp{
+		PageResponse: model.PageResponse{
+			PageIndex: req.Page,
+		},
+		Ticker:   token.Ticker,
+		TickerId: token.TkID,
+		List:     make([]*CBRC20ActivityEntry, 0, req.Limit),
+	}
+
+	// the deploy takes the first position of the first page
+	offset := (req.Page-1)*req.Limit - 1
+	limit := req.Limit + 1
+	if req.Page == 1 {
+		resp.List = append(resp.List, &CBRC20ActivityEntry{
+			Operation:     constants.OperationDeploy,
+			InscriptionId: token.TkID,
+			Owner:         token.Owner,
+			Amount:        formatCBRC20Amount(token.Max, token.Decimals),
+			Height:        token.Height,
+			Timestamp:     token.Timestamp,
+		})
+		offset = 0
+		limit--
+	}
+	mints, err := h.DB().FindCBRC20Mints(token.TkID, offset, limit)
+	if err != nil {
+		return err
+	}
+	resp.More = len(resp.List)+len(mints) > req.Limit
+	if resp.More {
+		mints = mints[:req.Limit-len(resp.List)]
+	}
+	for _, mint := range mints {
+		resp.List = append(resp.List, newCBRC20MintActivity(mint, token.Decimals))
+	}
+	ctx.JSON(http.StatusOK, resp)
+	return nil
+}
+
+func newCBRC20MintActivity(mint *tables.CBRC20Mint, decimals uint32) *CBRC20ActivityEntry {
+	return &CBRC20ActivityEntry{
+		Operation:     constants.OperationMint,
+		InscriptionId: mint.InscriptionId.String(),
+		Owner:         mint.Owner,
+		Amount:        formatCBRC20Amount(mint.Amount, decimals),
+		Height:        mint.Height,
+		Timestamp:     mint.Timestamp,
+	}
+}
@@ -0,0 +1,106 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/explorer-api/constants"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/model"
+	"github.com/shopspring/decimal"
+	"net/http"
+	"strings"
+)
+
+type CBRC20TokenHoldersResp struct {
+	model.PageResponse
+	Ticker   string               `json:"ticker"`
+	TickerId string               `json:"ticker_id"`
+	Decimals uint32               `json:"decimals"`
+	List     []*CBRC20HolderEntry `json:"list"`
+}
+
+type CBRC20HolderEntry struct {
+	Rank    int    `json:"rank"`
+	Address string `json:"address"`
+	Balance string `json:"balance"`
+	Share   string `json:"share"`
+}
+
+// CBRC20TokenHolders return a page of holders of a c-brc-20 token ranked by balance.
+// The share is the percent of the max supply, or of the minted amount if the supply is unlimited.
+func (h *Handler) CBRC20TokenHolders(ctx *gin.Context) {
+	ticker := strings.TrimSpace(ctx.Param("ticker"))
+	if !constants.TickNameRegexp.MatchString(ticker) {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "ticker is invalid"))
+		return
+	}
+	req := &BlockPageReq{}
+	if err := ctx.ShouldBindQuery(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, err.Error()))
+		return
+	}
+	if err := req.Check(); err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, err.Error()))
+		return
+	}
+	if err := h.doCBRC20TokenHolders(ctx, ticker, req); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doCBRC20TokenHolders(ctx *gin.Context, ticker string, req *BlockPageReq) error {
+	token, err := h.DB().GetCBRC20TokenByTicker(ticker)
+	if err != nil {
+		return err
+	}
+	if token.Id == 0 {
+		ctx.Status(http.StatusNotFound)
+		return nil
+	}
+	list, err := h.DB().FindCBRC20Holders(token.TkID, req.Page, req.Limit)
+	if err != nil {
+		return err
+	}
+
+	resp := &CBRC20TokenHoldersResp{
+		PageResponse: model.PageResponse{
+			PageIndex: req.Page,
+			More:      len(list) > req.Limit,
+		},
+		Ticker:   token.Ticker,
+		TickerId: token.TkID,
+		Decimals: token.Decimals,
+		List:     make([]*CBRC20HolderEntry, 0, len(list)),
+	}
+	if resp.More {
+		list = list[:req.Limit]
+	}
+
+	supply := token.Max
+	if supply == 0 {
+		supply = token.Minted
+	}
+	for i, holder := range list {
+		resp.List = append(resp.List, &CBRC20HolderEntry{
+			Rank:    (req.Page-1)*req.Limit + i + 1,
+			Address: holder.Owner,
+			Balance: formatCBRC20Amount(holder.Amount, token.Decimals),
+			Share:   cbrc20Share(holder.Amount, supply, token.Decimals),
+		})
+	}
+	ctx.JSON(http.StatusOK, resp)
+	return nil
+}
+
+// cbrc20Share return the percent of the supply held by a balance, it has as many fractional digits
+// as the token has decimals, and at least 4.
+func cbrc20Share(balance, supply uint64, decimals uint32) string {
+	places := int32(decimals)
+	if places < 4 {
+		places = 4
+	}
+	if supply == 0 {
+		return decimal.Zero.StringFixed(places)
+	}
+	return uint64ToDecimal(balance, 2).DivRound(uint64ToDecimal(supply, 0), places).StringFixed(places)
+}
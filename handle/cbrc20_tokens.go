@@ -0,0 +1,122 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/explorer-api/dao"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/model"
+	"github.com/shopspring/decimal"
+	"math/big"
+	"net/http"
+)
+
+type CBRC20TokensReq struct {
+	Page  int    `form:"page" binding:"omitempty,min=1"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=50"`
+	Sort  string `form:"sort" binding:"omitempty,oneof=deploy_time holders progress"`
+}
+
+func (req *CBRC20TokensReq) Check() error {
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+	if req.Sort == "" {
+		req.Sort = dao.CBRC20TokenSortDeployTime
+	}
+	return nil
+}
+
+type CBRC20TokensResp struct {
+	model.PageResponse
+	List []*CBRC20TokenEntry `json:"list"`
+}
+
+type CBRC20TokenEntry struct {
+	Ticker          string          `json:"ticker"`
+	TickerId        string          `json:"ticker_id"`
+	Max             string          `json:"max"`
+	Limit           string          `json:"limit"`
+	Decimals        uint32          `json:"decimals"`
+	Minted          string          `json:"minted"`
+	Percent         string          `json:"percent"`
+	MintCount       int64           `json:"mint_count"`
+	Holders         int64           `json:"holders"`
+	DeployHeight    uint32          `json:"deploy_height"`
+	DeployTimestamp int64           `json:"deploy_timestamp"`
+	CInsDescription CInsDescription `json:"c_ins_description"`
+}
+
+// CBRC20Tokens return a page of c-brc-20 tokens sorted by deploy time, holders or mint progress
+func (h *Handler) CBRC20Tokens(ctx *gin.Context) {
+	req := &CBRC20TokensReq{}
+	if err := ctx.ShouldBindQuery(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, err.Error()))
+		return
+	}
+	if err := req.Check(); err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, err.Error()))
+		return
+	}
+	if err := h.doCBRC20Tokens(ctx, req); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doCBRC20Tokens(ctx *gin.Context, req *CBRC20TokensReq) error {
+	list, err := h.DB().FindCBRC20Tokens(req.Sort, req.Page, req.Limit)
+	if err != nil {
+		return err
+	}
+
+	resp := &CBRC20TokensResp{
+		PageResponse: model.PageResponse{
+			PageIndex: req.Page,
+			More:      len(list) > req.Limit,
+		},
+		List: make([]*CBRC20TokenEntry, 0, len(list)),
+	}
+	if resp.More {
+		list = list[:req.Limit]
+	}
+	for _, token := range list {
+		resp.List = append(resp.List, newCBRC20TokenEntry(token))
+	}
+	ctx.JSON(http.StatusOK, resp)
+	return nil
+}
+
+func newCBRC20TokenEntry(token *dao.CBRC20TokenDetail) *CBRC20TokenEntry {
+	percent := decimal.Zero
+	if token.Max > 0 {
+		percent = uint64ToDecimal(token.Minted, 2).Div(uint64ToDecimal(token.Max, 0))
+	}
+	return &CBRC20TokenEntry{
+		Ticker:          token.Ticker,
+		TickerId:        token.TkID,
+		Max:             formatCBRC20Amount(token.Max, token.Decimals),
+		Limit:           formatCBRC20Amount(token.Limit, token.Decimals),
+		Decimals:        token.Decimals,
+		Minted:          formatCBRC20Amount(token.Minted, token.Decimals),
+		Percent:         percent.StringFixed(2),
+		MintCount:       token.MintCount,
+		Holders:         token.Holders,
+		DeployHeight:    token.Height,
+		DeployTimestamp: token.Timestamp,
+		CInsDescription: newCInsDescription(&token.CInsDescription),
+	}
+}
+
+// uint64ToDecimal return value * 10^exp as a decimal.
+func uint64ToDecimal(value uint64, exp int32) decimal.Decimal {
+	return decimal.NewFromBigInt(new(big.Int).SetUint64(value), exp)
+}
+
+// formatCBRC20Amount formats a token amount with the decimals of its deploy. The amounts are whole tokens
+// as the indexer stores them, so they're shown with as many fractional digits as the token has decimals.
+func formatCBRC20Amount(amount uint64, decimals uint32) string {
+	return uint64ToDecimal(amount, 0).StringFixed(int32(decimals))
+}
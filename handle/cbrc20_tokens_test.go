@@ -0,0 +1,40 @@
+package handle
+
+import "testing"
+
+func TestFormatCBRC20Amount(t *testing.T) {
+	tests := []struct {
+		amount   uint64
+		decimals uint32
+		want     string
+	}{
+		{amount: 21000000, decimals: 0, want: "21000000"},
+		{amount: 1000, decimals: 2, want: "1000.00"},
+		{amount: 18446744073709551615, decimals: 18, want: "18446744073709551615.000000000000000000"},
+		{amount: 0, decimals: 3, want: "0.000"},
+	}
+	for _, tt := range tests {
+		if got := formatCBRC20Amount(tt.amount, tt.decimals); got != tt.want {
+			t.Errorf("formatCBRC20Amount(%d, %d) = %s, want %s", tt.amount, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestCBRC20Share(t *testing.T) {
+	tests := []struct {
+		balance  uint64
+		supply   uint64
+		decimals uint32
+		want     string
+	}{
+		{balance: 1, supply: 3, decimals: 0, want: "33.3333"},
+		{balance: 1, supply: 3, decimals: 8, want: "33.33333333"},
+		{balance: 21000000, supply: 21000000, decimals: 18, want: "100.000000000000000000"},
+		{balance: 5, supply: 0, decimals: 2, want: "0.0000"},
+	}
+	for _, tt := range tests {
+		if got := cbrc20Share(tt.balance, tt.supply, tt.decimals); got != tt.want {
+			t.Errorf("cbrc20Share(%d, %d, %d) = %s, want %s", tt.balance, tt.supply, tt.decimals, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package handle
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/cins/pkg/util"
+	"github.com/inscription-c/explorer-api/constants"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/tables"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const contentEncodingBrotli = "br"
+
+// Content return the raw body of an inscription with its content type.
+// Range requests are supported so that audio, video and model inscriptions can be seeked.
+func (h *Handler) Content(ctx *gin.Context) {
+	inscriptionId := strings.TrimSpace(ctx.Param("inscription_id"))
+	if !constants.InscriptionIdRegexp.MatchString(inscriptionId) {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "inscription_id is invalid"))
+		return
+	}
+	if err := h.doContent(ctx, inscriptionId); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doContent(ctx *gin.Context, inscriptionId string) error {
+	ins, err := h.IndexerDB().GetInscriptionById(tables.StringToInscriptionId(inscriptionId))
+	if err != nil {
+		return err
+	}
+	if ins.Id == 0 {
+		ctx.Status(http.StatusNotFound)
+		return nil
+	}
+
+	contentType := constants.ContentTypeOctetStream
+	if ins.ContentType != "" {
+		contentType = constants.ContentType(ins.ContentType)
+	}
+
+	body := ins.Body
+	etag := ins.InscriptionId.String()
+	if ins.ContentEncoding != "" {
+		ctx.Header("Vary", "Accept-Encoding")
+		acceptEncoding := util.ParseAcceptEncoding(ctx.GetHeader("Accept-Encoding"))
+		switch {
+		case acceptEncoding.IsAccept(ins.ContentEncoding):
+			ctx.Header("Content-Encoding", ins.ContentEncoding)
+			etag = fmt.Sprintf("%s-%s", etag, ins.ContentEncoding)
+		case ins.ContentEncoding == contentEncodingBrotli:
+			body, err = decodeBrotli(ins.Body)
+			if err != nil {
+				return err
+			}
+		default:
+			ctx.Status(http.StatusNotAcceptable)
+			return nil
+		}
+	}
+
+	// inscription content never changes, so it can be cached forever
+	ctx.Header("Cache-Control", "public, max-age=1209600, immutable")
+	ctx.Header("ETag", fmt.Sprintf("\"%s\"", etag))
+	ctx.Header("Content-Type", contentType.String())
+	ctx.Header("Content-Security-Policy", contentSecurityPolicy())
+	http.ServeContent(ctx.Writer, ctx.Request, "", time.Unix(ins.Timestamp, 0), bytes.NewReader(body))
+	return nil
+}
+
+func decodeBrotli(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+	return io.ReadAll(brotli.NewReader(bytes.NewReader(data)))
+}
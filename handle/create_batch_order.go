@@ -0,0 +1,164 @@
+package handle
+
+import (
+	"fmt"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/cins/constants"
+	"github.com/inscription-c/cins/pkg/util"
+	constants2 "github.com/inscription-c/explorer-api/constants"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/model"
+	"github.com/inscription-c/explorer-api/tables"
+	"net/http"
+	"strconv"
+)
+
+type CreateBatchOrderReq struct {
+	Postage       int64               `json:"postage" binding:"min=330,max=10000"`
+	FeatRate      int64               `json:"fee_rate" binding:"gt=0"`
+	Inscriptions  []*BatchInscription `json:"inscriptions" binding:"required,min=1,max=100,dive"` // the inscriptions after the first one are cursed
+	RefundAddress string              `json:"refund_address"`
+	CallbackUrl   string              `json:"callback_url"`
+}
+
+type BatchInscription struct {
+	ContentType    string `json:"content_type" binding:"required"`
+	Content        []byte `json:"content" binding:"required"` // base64 encoded
+	Metadata       string `json:"metadata"`
+	L2NetWork      string `json:"l2_network"`
+	Contract       string `json:"contract"`
+	ReceiveAddress string `json:"receive_address" binding:"required"`
+}
+
+func (req *CreateBatchOrderReq) Check() error {
+	invalidParams := api_code.NewResponse(api_code.InvalidParams, "")
+	size := 0
+	for i, ins := range req.Inscriptions {
+		size += len(ins.Content)
+		if _, ok := constants2.MediaByContentType(constants2.ContentType(ins.ContentType)); !ok {
+			invalidParams.Message = fmt.Sprintf("unsupported content type of inscription %d", i)
+			return invalidParams
+		}
+		if ins.L2NetWork != "" || ins.Contract != "" {
+			if constants2.ChainName(ins.L2NetWork) == "" || ins.Contract == "" {
+				invalidParams.Message = fmt.Sprintf("invalid l2_network or contract of inscription %d", i)
+				return invalidParams
+			}
+		}
+		if _, err := btcutil.DecodeAddress(ins.ReceiveAddress, util.ActiveNet.Params); err != nil {
+			invalidParams.Message = fmt.Sprintf("invalid receive_address of inscription %d", i)
+			return invalidParams
+		}
+	}
+	if size > maxInscribeFileSize {
+		invalidParams.Message = "invalid total content size"
+		return invalidParams
+	}
+	if req.RefundAddress != "" {
+		if _, err := btcutil.DecodeAddress(req.RefundAddress, util.ActiveNet.Params); err != nil {
+			invalidParams.Message = "invalid refund_address"
+			return invalidParams
+		}
+	}
+	if msg := checkCallbackUrl(req.CallbackUrl); msg != "" {
+		invalidParams.Message = msg
+		return invalidParams
+	}
+	return nil
+}
+
+// CreateBatchOrder creates an order that inscribes many inscriptions with one payment. All the inscriptions
+// are revealed in one transaction, the inscription at index i goes to output i by its pointer.
+// The inscriptions after the first one are cursed by the indexer, the response tells how many with a notice.
+func (h *Handler) CreateBatchOrder(ctx *gin.Context) {
+	req := &CreateBatchOrderReq{}
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, err.Error()))
+		return
+	}
+	if err := req.Check(); err != nil {
+		ctx.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.doCreateBatchOrder(ctx, req); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doCreateBatchOrder(ctx *gin.Context, req *CreateBatchOrderReq) error {
+	envelopes := make([]*revealEnvelope, 0, len(req.Inscriptions))
+	txOuts := make([]*wire.TxOut, 0, len(req.Inscriptions))
+	items := make([]*tables.InscribeOrderItem, 0, len(req.Inscriptions))
+	for i, ins := range req.Inscriptions {
+		envelope := &revealEnvelope{
+			ContentType: constants.ContentType(ins.ContentType),
+			Body:        ins.Content,
+		}
+		if i > 0 {
+			envelope.Pointer = strconv.FormatInt(int64(i)*req.Postage, 10)
+		}
+		if ins.Metadata != "" {
+			metadata, err := model.EncodeJsonMetadata([]byte(ins.Metadata))
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidMetadata, fmt.Sprintf("inscription %d: %s", i, err)))
+				return nil
+			}
+			envelope.Metadata = metadata
+		}
+		if ins.L2NetWork != "" {
+			envelope.CInsDescription = &tables.CInsDescription{
+				Type:     constants.CInsDescriptionTypeBlockchain,
+				Chain:    ins.L2NetWork,
+				Contract: ins.Contract,
+			}
+		}
+		envelopes = append(envelopes, envelope)
+
+		destAddrScript, err := util.AddressScript(ins.ReceiveAddress, util.ActiveNet.Params)
+		if err != nil {
+			return err
+		}
+		txOuts = append(txOuts, wire.NewTxOut(req.Postage, destAddrScript))
+		items = append(items, &tables.InscribeOrderItem{
+			Index:          uint32(i),
+			ContentType:    ins.ContentType,
+			ReceiveAddress: ins.ReceiveAddress,
+		})
+	}
+
+	priKey, err := h.newRevealKey()
+	if err != nil {
+		return err
+	}
+	revealScript, err := revealScript(priKey.PubKey(), envelopes)
+	if err != nil {
+		return err
+	}
+	// the order is listed under the receive address of the first inscription
+	order, err := h.newRevealOrder(priKey, revealScript, txOuts, req.FeatRate, req.Inscriptions[0].ReceiveAddress)
+	if err != nil {
+		return err
+	}
+	order.RefundAddress = req.RefundAddress
+	callbackSecret, err := h.setCallback(order, req.CallbackUrl)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		item.OrderId = order.OrderId
+	}
+	if err := h.createInscribeOrder(order, items); err != nil {
+		return err
+	}
+
+	ctx.JSON(http.StatusOK, callbackResponse(multiInscriptionResponse(gin.H{
+		"order_id": order.OrderId,
+		"address":  order.RevealAddress,
+		"value":    order.RevealTxValue,
+	}, len(items)), callbackSecret))
+	return nil
+}
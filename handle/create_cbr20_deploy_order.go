@@ -1,11 +1,7 @@
 package handle
 
 import (
-	"bytes"
-	"encoding/hex"
-	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcutil"
-	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/gin-gonic/gin"
 	"github.com/inscription-c/cins/constants"
@@ -14,7 +10,6 @@ import (
 	"github.com/inscription-c/cins/pkg/util"
 	constants2 "github.com/inscription-c/explorer-api/constants"
 	"github.com/inscription-c/explorer-api/handle/api_code"
-	tables2 "github.com/inscription-c/explorer-api/tables"
 	"net/http"
 	"strconv"
 )
@@ -28,6 +23,8 @@ type CreateCbr20DeployOrderReq struct {
 	L2NetWork      string `json:"l2_network" binding:"required"`
 	Contract       string `json:"contract" binding:"required"`
 	ReceiveAddress string `json:"receive_address" binding:"required"`
+	RefundAddress  string `json:"refund_address"`
+	CallbackUrl    string `json:"callback_url"`
 }
 
 func (req *CreateCbr20DeployOrderReq) Check() error {
@@ -52,6 +49,16 @@ func (req *CreateCbr20DeployOrderReq) Check() error {
 		invalidParams.Message = "invalid receive_address"
 		return invalidParams
 	}
+	if req.RefundAddress != "" {
+		if _, err := btcutil.DecodeAddress(req.RefundAddress, util.ActiveNet.Params); err != nil {
+			invalidParams.Message = "invalid refund_address"
+			return invalidParams
+		}
+	}
+	if msg := checkCallbackUrl(req.CallbackUrl); msg != "" {
+		invalidParams.Message = msg
+		return invalidParams
+	}
 	return nil
 }
 
@@ -73,7 +80,7 @@ func (h *Handler) CreateCbr20DeployOrder(ctx *gin.Context) {
 }
 
 func (h *Handler) doCreateCbr20DeployOrder(ctx *gin.Context, req *CreateCbr20DeployOrderReq) error {
-	priKey, err := btcec.NewPrivateKey()
+	priKey, err := h.newRevealKey()
 	if err != nil {
 		return err
 	}
@@ -103,69 +110,28 @@ func (h *Handler) doCreateCbr20DeployOrder(ctx *gin.Context, req *CreateCbr20Dep
 		return err
 	}
 
-	// Generate the script address
-	controlBlock, taprootAddress, err := inscription.RevealScriptAddress(internalKey, revealScript)
-	if err != nil {
-		return err
-	}
-
-	// Create the witness for the transaction
-	revealTxWitness := make([][]byte, 0)
-	revealTxWitness = append(revealTxWitness, make([]byte, 64))
-	revealTxWitness = append(revealTxWitness, revealScript)
-	controlBlockBytes, err := controlBlock.ToBytes()
+	destAddrScript, err := util.AddressScript(req.ReceiveAddress, util.ActiveNet.Params)
 	if err != nil {
 		return err
 	}
-	revealTxWitness = append(revealTxWitness, controlBlockBytes)
-	taprootScript, err := txscript.PayToAddrScript(taprootAddress)
+	txOuts := []*wire.TxOut{wire.NewTxOut(req.Postage, destAddrScript)}
+	order, err := h.newRevealOrder(priKey, revealScript, txOuts, req.FeatRate, req.ReceiveAddress)
 	if err != nil {
 		return err
 	}
-
-	// Create the transaction input
-	revealTxIn := &wire.TxIn{
-		SignatureScript: taprootScript,
-		Witness:         revealTxWitness,
-		Sequence:        0xFFFFFFFD,
-	}
-
-	// Create the transaction output
-	destAddrScript, err := util.AddressScript(req.ReceiveAddress, util.ActiveNet.Params)
+	order.RefundAddress = req.RefundAddress
+	callbackSecret, err := h.setCallback(order, req.CallbackUrl)
 	if err != nil {
 		return err
 	}
-	revealTxOutput := wire.NewTxOut(req.Postage, destAddrScript)
-
-	// Create the reveal transaction
-	revealTx := wire.NewMsgTx(2)
-	revealTx.AddTxIn(revealTxIn)
-	revealTx.AddTxOut(revealTxOutput)
-
-	revealTxRaw := bytes.NewBufferString("")
-	if err := revealTx.Serialize(revealTxRaw); err != nil {
-		return err
-	}
-
-	txFee := inscription.CalculateTxFee(revealTx, req.FeatRate)
-	revealTxValue := txFee + req.Postage
-
-	order := &tables2.InscribeOrder{
-		RevealAddress:  taprootAddress.String(),
-		RevealPriKey:   hex.EncodeToString(priKey.Serialize()),
-		RevealTxRaw:    hex.EncodeToString(revealTxRaw.Bytes()),
-		RevealTxValue:  revealTxValue,
-		ReceiveAddress: req.ReceiveAddress,
-	}
-	order.InitOrderId()
-	if err := h.DB().CreateInscribeOrder(order); err != nil {
+	if err := h.createInscribeOrder(order, nil); err != nil {
 		return err
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
+	ctx.JSON(http.StatusOK, callbackResponse(gin.H{
 		"order_id": order.OrderId,
-		"address":  taprootAddress.String(),
-		"value":    revealTxValue,
-	})
+		"address":  order.RevealAddress,
+		"value":    order.RevealTxValue,
+	}, callbackSecret))
 	return nil
 }
@@ -0,0 +1,184 @@
+package handle
+
+import (
+	"encoding/json"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/gin-gonic/gin"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/inscription-c/cins/constants"
+	"github.com/inscription-c/cins/pkg/util"
+	constants2 "github.com/inscription-c/explorer-api/constants"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/model"
+	"github.com/inscription-c/explorer-api/tables"
+	"net/http"
+	"strconv"
+)
+
+type CreateCbr20MintOrderReq struct {
+	Postage        int64  `json:"postage" binding:"min=330,max=10000"`
+	FeatRate       int64  `json:"fee_rate" binding:"gt=0"`
+	Ticker         string `json:"ticker" binding:"required"`
+	Amount         string `json:"amount" binding:"required"`               // whole tokens, the decimals of a deploy only set how many fractional digits are shown
+	Repeat         int    `json:"repeat" binding:"omitempty,min=1,max=25"` // the mints after the first one are cursed
+	ReceiveAddress string `json:"receive_address" binding:"required"`
+	RefundAddress  string `json:"refund_address"`
+	CallbackUrl    string `json:"callback_url"`
+}
+
+func (req *CreateCbr20MintOrderReq) Check() error {
+	invalidParams := api_code.NewResponse(api_code.InvalidParams, "")
+	if !constants2.TickNameRegexp.MatchString(req.Ticker) {
+		invalidParams.Message = "invalid ticker"
+		return invalidParams
+	}
+	// cins keeps the max and the limit of a deploy as whole tokens, so the parser only counts whole mints
+	if amount, err := strconv.ParseUint(req.Amount, 10, 64); err != nil || amount == 0 {
+		invalidParams.Message = "invalid amount, it must be a positive whole number of tokens"
+		return invalidParams
+	}
+	if _, err := btcutil.DecodeAddress(req.ReceiveAddress, util.ActiveNet.Params); err != nil {
+		invalidParams.Message = "invalid receive_address"
+		return invalidParams
+	}
+	if req.Repeat == 0 {
+		req.Repeat = 1
+	}
+	if req.RefundAddress != "" {
+		if _, err := btcutil.DecodeAddress(req.RefundAddress, util.ActiveNet.Params); err != nil {
+			invalidParams.Message = "invalid refund_address"
+			return invalidParams
+		}
+	}
+	if msg := checkCallbackUrl(req.CallbackUrl); msg != "" {
+		invalidParams.Message = msg
+		return invalidParams
+	}
+	return nil
+}
+
+// CreateCbr20MintOrder creates an order that mints a deployed c-brc-20 token one or more times.
+// All the mints are revealed in one transaction, each of them goes to its own postage output.
+func (h *Handler) CreateCbr20MintOrder(ctx *gin.Context) {
+	req := &CreateCbr20MintOrderReq{}
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, err.Error()))
+		return
+	}
+	if err := req.Check(); err != nil {
+		ctx.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.doCreateCbr20MintOrder(ctx, req); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doCreateCbr20MintOrder(ctx *gin.Context, req *CreateCbr20MintOrderReq) error {
+	deploy, err := h.IndexerDB().GetDeployProtocolByTicker(req.Ticker)
+	if err != nil {
+		return err
+	}
+	if deploy.Id == 0 {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "ticker is not deployed"))
+		return nil
+	}
+	deployIns, err := h.IndexerDB().GetInscriptionBySequenceNum(deploy.SequenceNum)
+	if err != nil {
+		return err
+	}
+	if deployIns.Id == 0 {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "ticker is not deployed"))
+		return nil
+	}
+
+	amount := gconv.Uint64(req.Amount)
+	if deploy.Limit > 0 && amount > deploy.Limit {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "amount exceeds limit per mint"))
+		return nil
+	}
+	if deploy.Max > 0 {
+		minted, err := h.DB().SumCBRC20Minted(deploy.InscriptionId.String())
+		if err != nil {
+			return err
+		}
+		remaining := uint64(0)
+		if minted < deploy.Max {
+			remaining = deploy.Max - minted
+		}
+		if amount > remaining/uint64(req.Repeat) {
+			ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "amount exceeds remaining supply"))
+			return nil
+		}
+	}
+
+	body, err := json.Marshal(&model.CBRC20Mint{
+		Protocol:  constants.ProtocolCBRC20,
+		Operation: constants.OperationMint,
+		Tick:      deploy.Ticker,
+		Amount:    req.Amount,
+	})
+	if err != nil {
+		return err
+	}
+
+	priKey, err := h.newRevealKey()
+	if err != nil {
+		return err
+	}
+	revealScript, err := mintRevealScript(priKey.PubKey(), &deployIns.CInsDescription, body, req.Repeat, req.Postage)
+	if err != nil {
+		return err
+	}
+
+	destAddrScript, err := util.AddressScript(req.ReceiveAddress, util.ActiveNet.Params)
+	if err != nil {
+		return err
+	}
+	txOuts := make([]*wire.TxOut, 0, req.Repeat)
+	for i := 0; i < req.Repeat; i++ {
+		txOuts = append(txOuts, wire.NewTxOut(req.Postage, destAddrScript))
+	}
+	order, err := h.newRevealOrder(priKey, revealScript, txOuts, req.FeatRate, req.ReceiveAddress)
+	if err != nil {
+		return err
+	}
+	order.RefundAddress = req.RefundAddress
+	callbackSecret, err := h.setCallback(order, req.CallbackUrl)
+	if err != nil {
+		return err
+	}
+	if err := h.createInscribeOrder(order, nil); err != nil {
+		return err
+	}
+
+	ctx.JSON(http.StatusOK, callbackResponse(multiInscriptionResponse(gin.H{
+		"order_id": order.OrderId,
+		"address":  order.RevealAddress,
+		"value":    order.RevealTxValue,
+	}, req.Repeat), callbackSecret))
+	return nil
+}
+
+// mintRevealScript builds a reveal script with one mint envelope per repeat. Every envelope after
+// the first one has a pointer to the offset of its own postage output, so that the mints are not
+// inscribed on the same sat. Those mints are cursed by the indexer, see cursedNotice.
+func mintRevealScript(internalKey *btcec.PublicKey, desc *tables.CInsDescription, body []byte, repeat int, postage int64) ([]byte, error) {
+	envelopes := make([]*revealEnvelope, 0, repeat)
+	for i := 0; i < repeat; i++ {
+		envelope := &revealEnvelope{
+			CInsDescription: desc,
+			ContentType:     constants.ContentTypeJson,
+			Body:            body,
+		}
+		if i > 0 {
+			envelope.Pointer = strconv.FormatInt(int64(i)*postage, 10)
+		}
+		envelopes = append(envelopes, envelope)
+	}
+	return revealScript(internalKey, envelopes)
+}
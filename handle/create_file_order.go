@@ -0,0 +1,209 @@
+package handle
+
+import (
+	"bytes"
+	"errors"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/cins/constants"
+	"github.com/inscription-c/cins/pkg/util"
+	constants2 "github.com/inscription-c/explorer-api/constants"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/model"
+	"github.com/inscription-c/explorer-api/tables"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+)
+
+// maxInscribeFileSize keeps the reveal transaction under the standard transaction weight.
+const maxInscribeFileSize = 360 * 1024
+
+type CreateFileOrderReq struct {
+	Postage        int64                 `form:"postage" binding:"min=330,max=10000"`
+	FeatRate       int64                 `form:"fee_rate" binding:"gt=0"`
+	File           *multipart.FileHeader `form:"file" binding:"required"`
+	ContentType    string                `form:"content_type"`
+	Compress       bool                  `form:"compress"`
+	Metadata       string                `form:"metadata"`
+	Pointer        string                `form:"pointer"`
+	L2NetWork      string                `form:"l2_network"`
+	Contract       string                `form:"contract"`
+	ReceiveAddress string                `form:"receive_address" binding:"required"`
+	RefundAddress  string                `form:"refund_address"`
+	CallbackUrl    string                `form:"callback_url"`
+
+	media constants2.Media
+}
+
+func (req *CreateFileOrderReq) Check() error {
+	invalidParams := api_code.NewResponse(api_code.InvalidParams, "")
+	if req.File.Size == 0 || req.File.Size > maxInscribeFileSize {
+		invalidParams.Message = "invalid file size"
+		return invalidParams
+	}
+	var ok bool
+	if req.ContentType != "" {
+		req.media, ok = constants2.MediaByContentType(constants2.ContentType(req.ContentType))
+	} else {
+		req.media, ok = constants2.MediaByExtension(filepath.Ext(req.File.Filename))
+	}
+	if !ok {
+		invalidParams.Message = "unsupported content type"
+		return invalidParams
+	}
+	if req.Pointer != "" {
+		pointer, err := strconv.ParseUint(req.Pointer, 10, 64)
+		if err != nil || pointer >= uint64(req.Postage) {
+			invalidParams.Message = "invalid pointer"
+			return invalidParams
+		}
+	}
+	if req.L2NetWork != "" || req.Contract != "" {
+		if constants2.ChainName(req.L2NetWork) == "" {
+			invalidParams.Message = "invalid l2_network"
+			return invalidParams
+		}
+		if req.Contract == "" {
+			invalidParams.Message = "invalid contract"
+			return invalidParams
+		}
+	}
+	if _, err := btcutil.DecodeAddress(req.ReceiveAddress, util.ActiveNet.Params); err != nil {
+		invalidParams.Message = "invalid receive_address"
+		return invalidParams
+	}
+	if req.RefundAddress != "" {
+		if _, err := btcutil.DecodeAddress(req.RefundAddress, util.ActiveNet.Params); err != nil {
+			invalidParams.Message = "invalid refund_address"
+			return invalidParams
+		}
+	}
+	if msg := checkCallbackUrl(req.CallbackUrl); msg != "" {
+		invalidParams.Message = msg
+		return invalidParams
+	}
+	return nil
+}
+
+// CreateFileOrder creates an order that inscribes an uploaded file. The content type is taken from
+// the content_type field or the file extension, the metadata is json and is inscribed as CBOR.
+func (h *Handler) CreateFileOrder(ctx *gin.Context) {
+	req := &CreateFileOrderReq{}
+	if err := ctx.ShouldBind(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, err.Error()))
+		return
+	}
+	if err := req.Check(); err != nil {
+		ctx.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.doCreateFileOrder(ctx, req); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doCreateFileOrder(ctx *gin.Context, req *CreateFileOrderReq) error {
+	file, err := req.File.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	body, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+
+	envelope := &revealEnvelope{
+		ContentType: constants.ContentType(req.media.ContentType),
+		Pointer:     req.Pointer,
+		Body:        body,
+	}
+	if req.Metadata != "" {
+		envelope.Metadata, err = model.EncodeJsonMetadata([]byte(req.Metadata))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidMetadata, err.Error()))
+			return nil
+		}
+	}
+	if req.L2NetWork != "" {
+		envelope.CInsDescription = &tables.CInsDescription{
+			Type:     constants.CInsDescriptionTypeBlockchain,
+			Chain:    req.L2NetWork,
+			Contract: req.Contract,
+		}
+	}
+	if req.Compress {
+		compressed, err := compressBrotli(body, req.media.BrotliMode)
+		if err != nil {
+			return err
+		}
+		if len(compressed) < len(body) {
+			envelope.Body = compressed
+			envelope.ContentEncoding = contentEncodingBrotli
+		}
+	}
+
+	priKey, err := h.newRevealKey()
+	if err != nil {
+		return err
+	}
+	revealScript, err := revealScript(priKey.PubKey(), []*revealEnvelope{envelope})
+	if err != nil {
+		return err
+	}
+
+	destAddrScript, err := util.AddressScript(req.ReceiveAddress, util.ActiveNet.Params)
+	if err != nil {
+		return err
+	}
+	txOuts := []*wire.TxOut{wire.NewTxOut(req.Postage, destAddrScript)}
+	order, err := h.newRevealOrder(priKey, revealScript, txOuts, req.FeatRate, req.ReceiveAddress)
+	if err != nil {
+		return err
+	}
+	order.RefundAddress = req.RefundAddress
+	callbackSecret, err := h.setCallback(order, req.CallbackUrl)
+	if err != nil {
+		return err
+	}
+	if err := h.createInscribeOrder(order, nil); err != nil {
+		return err
+	}
+
+	ctx.JSON(http.StatusOK, callbackResponse(gin.H{
+		"order_id":         order.OrderId,
+		"address":          order.RevealAddress,
+		"value":            order.RevealTxValue,
+		"content_type":     req.media.ContentType,
+		"content_encoding": envelope.ContentEncoding,
+	}, callbackSecret))
+	return nil
+}
+
+// brotli settings of the cins inscribe command.
+const (
+	brotliQuality = 11
+	brotliLGWin   = 24
+)
+
+// compressBrotli compresses a body in the brotli mode of its media and checks the round trip.
+func compressBrotli(body []byte, mode constants2.BrotliMode) ([]byte, error) {
+	compressed, err := encodeBrotli(body, mode)
+	if err != nil {
+		return nil, err
+	}
+	decompressed, err := decodeBrotli(compressed)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(body, decompressed) {
+		return nil, errors.New("brotli round trip failed")
+	}
+	return compressed, nil
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/inscription-c/cins/inscription/log"
 	"github.com/inscription-c/explorer-api/dao"
 	"github.com/inscription-c/explorer-api/dao/indexer"
+	"github.com/inscription-c/explorer-api/keystore"
+	"github.com/inscription-c/explorer-api/stream"
 	"net/http"
 	"os"
 )
@@ -21,6 +23,9 @@ type Options struct {
 	db      *dao.DB
 	indexer *indexer.DB
 	cli     *rpcclient.Client
+	stream  *stream.Hub
+	keyring *keystore.Keyring
+	hdKey   *keystore.HDKey
 }
 
 // Option is a function type that sets a specific option in an Options struct.
@@ -72,6 +77,27 @@ func WithClient(cli *rpcclient.Client) func(*Options) {
 	}
 }
 
+// WithStream sets the hub of the stream endpoints.
+func WithStream(hub *stream.Hub) func(*Options) {
+	return func(options *Options) {
+		options.stream = hub
+	}
+}
+
+// WithKeyring sets the keyring the reveal private keys of new orders are encrypted by.
+func WithKeyring(keyring *keystore.Keyring) func(*Options) {
+	return func(options *Options) {
+		options.keyring = keyring
+	}
+}
+
+// WithHDKey sets the HD key the reveal private keys of new orders are derived from.
+func WithHDKey(hdKey *keystore.HDKey) func(*Options) {
+	return func(options *Options) {
+		options.hdKey = hdKey
+	}
+}
+
 // Handler is a struct that holds the options for handling requests.
 type Handler struct {
 	options *Options
@@ -92,6 +118,21 @@ func (h *Handler) RpcClient() *rpcclient.Client {
 	return h.options.cli
 }
 
+// Stream is a method that returns the stream hub from the options of a Handler.
+func (h *Handler) Stream() *stream.Hub {
+	return h.options.stream
+}
+
+// Keyring is a method that returns the keyring of the reveal private keys from the options of a Handler.
+func (h *Handler) Keyring() *keystore.Keyring {
+	return h.options.keyring
+}
+
+// HDKey is a method that returns the HD key of the reveal private keys from the options of a Handler.
+func (h *Handler) HDKey() *keystore.HDKey {
+	return h.options.hdKey
+}
+
 // Engine is a method that returns the gin engine from the options of a Handler.
 func (h *Handler) Engine() *gin.Engine {
 	return h.options.engin
@@ -122,6 +163,9 @@ func New(opts ...Option) (*Handler, error) {
 	if h.options.engin == nil {
 		h.options.engin = gin.New()
 	}
+	if h.options.stream == nil {
+		h.options.stream = stream.NewHub(0)
+	}
 	return h, nil
 }
 
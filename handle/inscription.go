@@ -0,0 +1,171 @@
+package handle
+
+import (
+	"github.com/btcsuite/btcd/wire"
+	"github.com/gin-gonic/gin"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/inscription-c/cins/inscription/index"
+	"github.com/inscription-c/explorer-api/constants"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/log"
+	"github.com/inscription-c/explorer-api/model"
+	"github.com/inscription-c/explorer-api/tables"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const SearchTypeSequenceNumber SearchType = "sequence_number"
+
+type InscriptionResp struct {
+	InscriptionId     string          `json:"inscription_id"`
+	InscriptionNumber int64           `json:"inscription_number"`
+	SequenceNumber    int64           `json:"sequence_number"`
+	Previous          *int64          `json:"previous"`
+	Next              *int64          `json:"next"`
+	Charms            []string        `json:"charms"`
+	ContentType       string          `json:"content_type"`
+	ContentEncoding   string          `json:"content_encoding"`
+	MediaType         string          `json:"media_type"`
+	ContentLength     uint32          `json:"content_length"`
+	ContentProtocol   string          `json:"content_protocol"`
+	Metadata          interface{}     `json:"metadata"`
+	Pointer           int32           `json:"pointer"`
+	GenesisHeight     uint32          `json:"genesis_height"`
+	GenesisFee        uint64          `json:"genesis_fee"`
+	GenesisTimestamp  string          `json:"genesis_timestamp"`
+	OwnerOutput       string          `json:"owner_output"`
+	OwnerAddress      string          `json:"owner_address"`
+	SatPoint          string          `json:"satpoint"`
+	Sat               string          `json:"sat"`
+	CInsDescription   CInsDescription `json:"c_ins_description"`
+}
+
+// Inscription return the detail of an inscription, the id can be an inscription id,
+// an inscription number or a sequence number when query type=sequence_number.
+func (h *Handler) Inscription(ctx *gin.Context) {
+	id := strings.TrimSpace(ctx.Param("id"))
+	if id == "" {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "id is required"))
+		return
+	}
+	if err := h.doInscription(ctx, id, SearchType(ctx.Query("type"))); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doInscription(ctx *gin.Context, id string, searchType SearchType) error {
+	ins, ok, err := h.findInscription(ctx, id, searchType)
+	if err != nil || !ok {
+		return err
+	}
+
+	resp := &InscriptionResp{
+		InscriptionId:     ins.InscriptionId.String(),
+		InscriptionNumber: ins.InscriptionNum,
+		SequenceNumber:    ins.SequenceNum,
+		Charms:            index.CharmsAll.Titles(ins.Charms),
+		ContentType:       ins.ContentType,
+		ContentEncoding:   ins.ContentEncoding,
+		MediaType:         ins.MediaType,
+		ContentLength:     ins.ContentSize,
+		ContentProtocol:   ins.ContentProtocol,
+		Pointer:           ins.Pointer,
+		GenesisHeight:     ins.Height,
+		GenesisFee:        ins.Fee,
+		GenesisTimestamp:  time.Unix(ins.Timestamp, 0).UTC().Format(time.RFC3339),
+		OwnerOutput:       model.NewOutPoint(ins.TxId, ins.Index).String(),
+		OwnerAddress:      ins.Owner,
+		Sat:               gconv.String(ins.Sat),
+		CInsDescription:   newCInsDescription(&ins.CInsDescription),
+	}
+
+	metadata, err := model.DecodeMetadata(ins.Metadata)
+	if err != nil {
+		log.Log.Warnf("DecodeMetadata inscription: %s err: %s", resp.InscriptionId, err)
+	}
+	resp.Metadata = metadata
+
+	previous, err := h.IndexerDB().GetInscriptionBySequenceNum(ins.SequenceNum - 1)
+	if err != nil {
+		return err
+	}
+	if previous.Id > 0 {
+		resp.Previous = &previous.InscriptionNum
+	}
+	next, err := h.IndexerDB().GetInscriptionBySequenceNum(ins.SequenceNum + 1)
+	if err != nil {
+		return err
+	}
+	if next.Id > 0 {
+		resp.Next = &next.InscriptionNum
+	}
+
+	resp.SatPoint, _, err = h.inscriptionLocation(&ins)
+	if err != nil {
+		return err
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+	return nil
+}
+
+// findInscription resolves an inscription id, inscription number or sequence number to an inscription.
+// It writes the bad request or not found response itself and returns false in that case.
+func (h *Handler) findInscription(ctx *gin.Context, id string, searchType SearchType) (ins tables.Inscriptions, ok bool, err error) {
+	if constants.InscriptionIdRegexp.MatchString(id) {
+		ins, err = h.IndexerDB().GetInscriptionById(tables.StringToInscriptionId(id))
+	} else {
+		num, parseErr := strconv.ParseInt(id, 10, 64)
+		if parseErr != nil {
+			ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "id is invalid"))
+			return
+		}
+		switch searchType {
+		case "", SearchTypeInscriptionNumber:
+			ins, err = h.IndexerDB().GetInscriptionByInscriptionNum(num)
+		case SearchTypeSequenceNumber:
+			ins, err = h.IndexerDB().GetInscriptionBySequenceNum(num)
+		default:
+			ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "type is invalid"))
+			return
+		}
+	}
+	if err != nil {
+		return
+	}
+	if ins.Id == 0 {
+		ctx.Status(http.StatusNotFound)
+		return
+	}
+	ok = true
+	return
+}
+
+// inscriptionLocation return the current satpoint and output of an inscription.
+// The satpoint is the null outpoint when the inscription is not bound to a sat.
+func (h *Handler) inscriptionLocation(ins *tables.Inscriptions) (satPoint, output string, err error) {
+	location, err := h.IndexerDB().GetSatPointBySequenceNum(ins.SequenceNum)
+	if err != nil {
+		return
+	}
+	if location.Id == 0 {
+		satPoint = tables.FormatSatPoint(wire.OutPoint{}.String(), 0)
+		output = model.NewOutPoint(ins.TxId, ins.Index).String()
+		return
+	}
+	satPoint = location.String()
+	output = location.Outpoint
+	return
+}
+
+func newCInsDescription(desc *tables.CInsDescription) CInsDescription {
+	return CInsDescription{
+		Type:      desc.Type,
+		Chain:     desc.Chain,
+		ChainName: constants.ChainName(desc.Chain),
+		Contract:  desc.Contract,
+	}
+}
@@ -0,0 +1,52 @@
+package handle
+
+import (
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/model"
+	"net/http"
+	"strings"
+)
+
+type InscriptionMetadataResp struct {
+	InscriptionId string      `json:"inscription_id"`
+	Hex           string      `json:"hex"`
+	Metadata      interface{} `json:"metadata"`
+}
+
+// InscriptionMetadata return the decoded CBOR metadata of an inscription
+func (h *Handler) InscriptionMetadata(ctx *gin.Context) {
+	id := strings.TrimSpace(ctx.Param("id"))
+	if id == "" {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "id is required"))
+		return
+	}
+	if err := h.doInscriptionMetadata(ctx, id, SearchType(ctx.Query("type"))); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doInscriptionMetadata(ctx *gin.Context, id string, searchType SearchType) error {
+	ins, ok, err := h.findInscription(ctx, id, searchType)
+	if err != nil || !ok {
+		return err
+	}
+	if len(ins.Metadata) == 0 {
+		ctx.Status(http.StatusNotFound)
+		return nil
+	}
+
+	metadata, err := model.DecodeMetadata(ins.Metadata)
+	if err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, api_code.NewResponse(api_code.InvalidMetadata, err.Error()))
+		return nil
+	}
+	ctx.JSON(http.StatusOK, &InscriptionMetadataResp{
+		InscriptionId: ins.InscriptionId.String(),
+		Hex:           hex.EncodeToString(ins.Metadata),
+		Metadata:      metadata,
+	})
+	return nil
+}
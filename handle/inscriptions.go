@@ -34,6 +34,11 @@ type InscriptionsReq struct {
 	Types           []string `json:"types" binding:"omitempty,dive,oneof=image text json html"`
 	InscriptionType string   `json:"inscription_type" binding:"omitempty,oneof=c-brc-20"`
 	Charms          []string `json:"charms" binding:"omitempty,dive,oneof=cursed"`
+	Rarity          string   `json:"rarity" binding:"omitempty,oneof=common uncommon rare epic legendary mythic"`
+	Cursor          string   `json:"cursor"`
+	WithTotal       bool     `json:"with_total"` // return the total of a cursor request, it is slow on large filtered results
+
+	cursor *model.Cursor
 }
 
 func (req *InscriptionsReq) Check() error {
@@ -46,14 +51,30 @@ func (req *InscriptionsReq) Check() error {
 	if req.Order == "" {
 		req.Order = "newest"
 	}
+	if req.Cursor != "" {
+		cursor, err := model.StringToCursor(req.Cursor)
+		if err != nil {
+			return err
+		}
+		if cursor.Order != req.Order {
+			return model.ErrInvalidCursor
+		}
+		req.cursor = cursor
+	}
 	return nil
 }
 
 type InscriptionsResp struct {
-	SearchType SearchType          `json:"search_type"`
-	Page       int                 `json:"page"`
-	Total      int                 `json:"total"`
-	List       []*InscriptionEntry `json:"list"`
+	SearchType       SearchType          `json:"search_type"`
+	Page             int                 `json:"page"`
+	Total            *int64              `json:"total"`
+	TotalApproximate bool                `json:"total_approximate"`
+	NextCursor       string              `json:"next_cursor"`
+	List             []*InscriptionEntry `json:"list"`
+}
+
+func (r *InscriptionsResp) setTotal(total int64) {
+	r.Total = &total
 }
 
 type InscriptionEntry struct {
@@ -118,6 +139,10 @@ func (h *Handler) doInscriptions(ctx *gin.Context, req *InscriptionsReq) error {
 		ContentTypes:    contentTypes,
 		Charms:          req.Charms,
 		InscriptionType: req.InscriptionType,
+		Rarity:          req.Rarity,
+	}
+	if req.cursor != nil {
+		searParams.AfterId = req.cursor.Id
 	}
 
 	req.Search = strings.TrimSpace(req.Search)
@@ -135,7 +160,7 @@ func (h *Handler) doInscriptions(ctx *gin.Context, req *InscriptionsReq) error {
 				ctx.Status(http.StatusNotFound)
 				return nil
 			}
-			resp.Total = 1
+			resp.setTotal(1)
 			resp.SearchType = SearchTypeInscriptionId
 			resp.List = append(resp.List, insToScanEntry(&ins))
 			ctx.JSON(http.StatusOK, resp)
@@ -152,7 +177,7 @@ func (h *Handler) doInscriptions(ctx *gin.Context, req *InscriptionsReq) error {
 				ctx.Status(http.StatusNotFound)
 				return nil
 			}
-			resp.Total = 1
+			resp.setTotal(1)
 			resp.SearchType = SearchTypeInscriptionNumber
 			resp.List = append(resp.List, insToScanEntry(&ins))
 			ctx.JSON(http.StatusOK, resp)
@@ -168,6 +193,19 @@ func (h *Handler) doInscriptions(ctx *gin.Context, req *InscriptionsReq) error {
 		}
 	}
 
+	// page-number requests get the exact total. Counting scans all matched rows, so cursor requests only get
+	// the total on request, and for them the total of all inscriptions is estimated from the table statistics.
+	if req.cursor == nil || searParams.Filtered() && req.WithTotal {
+		searParams.CountTotal = true
+	} else if req.WithTotal {
+		total, err := h.IndexerDB().ApproximateInscriptionsNum()
+		if err != nil {
+			return err
+		}
+		resp.setTotal(total)
+		resp.TotalApproximate = true
+	}
+
 	list, total, err := h.IndexerDB().SearchInscriptions(searParams)
 	if err != nil {
 		return err
@@ -177,7 +215,14 @@ func (h *Handler) doInscriptions(ctx *gin.Context, req *InscriptionsReq) error {
 		return nil
 	}
 
-	resp.Total = int(total)
+	if searParams.CountTotal {
+		resp.setTotal(total)
+	}
+	if len(list) > req.Limit {
+		list = list[:req.Limit]
+		cursor := &model.Cursor{Id: list[len(list)-1].Id, Order: req.Order}
+		resp.NextCursor = cursor.String()
+	}
 
 	for _, ins := range list {
 		resp.List = append(resp.List, insToScanEntry(ins))
@@ -198,12 +243,7 @@ func insToScanEntry(ins *tables.Inscriptions) *InscriptionEntry {
 		OwnerOutput:       model.NewOutPoint(ins.TxId, ins.Index).String(),
 		OwnerAddress:      ins.Owner,
 		Sat:               gconv.String(ins.Sat),
-		CInsDescription: CInsDescription{
-			Type:      ins.CInsDescription.Type,
-			Chain:     ins.CInsDescription.Chain,
-			ChainName: constants.Coins[ins.CInsDescription.Chain].ChainName,
-			Contract:  ins.CInsDescription.Contract,
-		},
-		ContentProtocol: ins.ContentProtocol,
+		CInsDescription:   newCInsDescription(&ins.CInsDescription),
+		ContentProtocol:   ins.ContentProtocol,
 	}
 }
@@ -4,23 +4,40 @@ import (
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"regexp"
+	"strings"
 )
 
-func Cors(origins ...string) gin.HandlerFunc {
+// Cors allows the origins that match any of the origin patterns to read the responses with credentials.
+// The paths under the public prefixes are readable by any origin without credentials, the header is sent
+// even without an Origin so that the cached responses can be read by any origin. They're used by /content and
+// the recursive endpoints, sandboxed html inscriptions fetch them with an opaque origin, which is sent as Origin: null.
+func Cors(publicPrefixes []string, origins ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		method := c.Request.Method
 		origin := c.Request.Header.Get("Origin")
+		public := false
+		for _, prefix := range publicPrefixes {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				public = true
+				break
+			}
+		}
+		if public {
+			c.Header("Access-Control-Allow-Origin", "*")
+		}
 		if origin != "" {
-			for _, v := range origins {
-				if ok, err := regexp.MatchString(v, origin); err == nil && ok {
-					c.Header("Access-Control-Allow-Origin", origin)
-					break
+			if !public {
+				for _, v := range origins {
+					if ok, err := regexp.MatchString(v, origin); err == nil && ok {
+						c.Header("Access-Control-Allow-Origin", origin)
+						break
+					}
 				}
+				c.Header("Access-Control-Allow-Credentials", "true")
 			}
 			c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE, UPDATE")
-			c.Header("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, Authorization")
-			c.Header("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, Cache-Control, Content-Language, Content-Type")
-			c.Header("Access-Control-Allow-Credentials", "true")
+			c.Header("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, Authorization, Range")
+			c.Header("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, Cache-Control, Content-Language, Content-Type, Content-Encoding, Content-Range, Accept-Ranges, ETag")
 		}
 		if method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
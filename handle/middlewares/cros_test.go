@@ -0,0 +1,62 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCorsPublicPrefixes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(Cors([]string{"/content/", "/r/"}, "^https://explorer\\.example$"))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	engine.GET("/content/:inscription_id", ok)
+	engine.GET("/r/blockheight", ok)
+	engine.GET("/inscription/:id", ok)
+
+	tests := []struct {
+		method          string
+		path            string
+		origin          string
+		wantCode        int
+		wantOrigin      string
+		wantCredentials string
+	}{
+		{method: http.MethodGet, path: "/content/abci0", origin: "null", wantCode: http.StatusOK, wantOrigin: "*"},
+		{method: http.MethodGet, path: "/content/abci0", origin: "", wantCode: http.StatusOK, wantOrigin: "*"},
+		{method: http.MethodGet, path: "/r/blockheight", origin: "null", wantCode: http.StatusOK, wantOrigin: "*"},
+		{method: http.MethodGet, path: "/r/blockheight", origin: "https://explorer.example", wantCode: http.StatusOK, wantOrigin: "*"},
+		{method: http.MethodOptions, path: "/content/abci0", origin: "null", wantCode: http.StatusNoContent, wantOrigin: "*"},
+		{method: http.MethodOptions, path: "/r/blockheight", origin: "null", wantCode: http.StatusNoContent, wantOrigin: "*"},
+		{method: http.MethodGet, path: "/inscription/abci0", origin: "null", wantCode: http.StatusOK, wantOrigin: "", wantCredentials: "true"},
+		{method: http.MethodGet, path: "/inscription/abci0", origin: "https://explorer.example", wantCode: http.StatusOK,
+			wantOrigin: "https://explorer.example", wantCredentials: "true"},
+		{method: http.MethodOptions, path: "/inscription/abci0", origin: "https://explorer.example", wantCode: http.StatusNoContent,
+			wantOrigin: "https://explorer.example", wantCredentials: "true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path+" "+tt.origin, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.method == http.MethodOptions {
+				req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+				req.Header.Set("Access-Control-Request-Headers", "range")
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+			if w.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d", w.Code, tt.wantCode)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != tt.wantCredentials {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want %q", got, tt.wantCredentials)
+			}
+		})
+	}
+}
@@ -212,8 +212,10 @@ func (p *Prometheus) runServer() {
 }
 
 func (p *Prometheus) getMetrics() []byte {
-	response, _ := http.Get(p.Ppg.MetricsURL)
-
+	response, err := http.Get(p.Ppg.MetricsURL)
+	if err != nil {
+		return nil
+	}
 	defer response.Body.Close()
 	body, _ := ioutil.ReadAll(response.Body)
 
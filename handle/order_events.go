@@ -0,0 +1,61 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/tables"
+	"net/http"
+)
+
+type OrderEventResp struct {
+	Type      tables.OrderEventType `json:"type"`
+	Status    tables.OrderStatus    `json:"status"`
+	Height    uint32                `json:"height"`
+	TxId      string                `json:"tx_id"`
+	Amount    int64                 `json:"amount"`
+	Message   string                `json:"message"`
+	Timestamp int64                 `json:"timestamp"`
+}
+
+// OrderEvents returns the timeline of an order, from its creation to its inscription or refund.
+func (h *Handler) OrderEvents(ctx *gin.Context) {
+	orderId := ctx.Param("order_id")
+	if orderId == "" {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "order_id is required"))
+		return
+	}
+	if err := h.doOrderEvents(ctx, orderId); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doOrderEvents(ctx *gin.Context, orderId string) error {
+	order, err := h.DB().GetInscribeOrderByOrderId(orderId)
+	if err != nil {
+		return err
+	}
+	if order.Id == 0 {
+		ctx.Status(http.StatusNotFound)
+		return nil
+	}
+
+	events, err := h.DB().FindInscribeOrderEvents(order.OrderId)
+	if err != nil {
+		return err
+	}
+	resp := make([]*OrderEventResp, 0, len(events))
+	for _, event := range events {
+		resp = append(resp, &OrderEventResp{
+			Type:      event.Type,
+			Status:    event.Status,
+			Height:    event.Height,
+			TxId:      event.TxId,
+			Amount:    event.Amount,
+			Message:   event.Message,
+			Timestamp: event.CreatedAt.Unix(),
+		})
+	}
+	ctx.JSON(http.StatusOK, resp)
+	return nil
+}
@@ -8,8 +8,25 @@ import (
 )
 
 type OrderStatusResp struct {
-	Status        tables.OrderStatus   `json:"status"`
-	InscriptionId tables.InscriptionId `json:"inscription_id"`
+	Status        tables.OrderStatus      `json:"status"`
+	InscriptionId tables.InscriptionId    `json:"inscription_id"`
+	RefundTxId    string                  `json:"refund_tx_id,omitempty"`
+	ChangeValue   int64                   `json:"change_value"`
+	CommitTxId    string                  `json:"commit_tx_id,omitempty"`
+	RevealTxId    string                  `json:"reveal_tx_id,omitempty"`
+	FeeRate       int64                   `json:"fee_rate"`
+	BumpCount     int                     `json:"bump_count"`
+	Required      int64                   `json:"required"`
+	Paid          int64                   `json:"paid"`
+	Missing       int64                   `json:"missing"`
+	Inscriptions  []*OrderInscriptionResp `json:"inscriptions,omitempty"`
+}
+
+type OrderInscriptionResp struct {
+	Index          uint32 `json:"index"`
+	InscriptionId  string `json:"inscription_id"`
+	ContentType    string `json:"content_type"`
+	ReceiveAddress string `json:"receive_address"`
 }
 
 func (h *Handler) OrderStatus(ctx *gin.Context) {
@@ -37,6 +54,45 @@ func (h *Handler) doOrderStatus(ctx *gin.Context, orderId string) error {
 	resp := &OrderStatusResp{
 		Status:        order.Status,
 		InscriptionId: order.InscriptionId,
+		RefundTxId:    order.RefundTxId,
+		ChangeValue:   order.ChangeValue,
+		CommitTxId:    order.CommitTxId,
+		RevealTxId:    order.RevealTxId,
+		FeeRate:       order.FeeRate,
+		BumpCount:     order.BumpCount,
+	}
+
+	// each top-up payment adds an input to the reveal tx, so the missing value includes the fee of the next input
+	payments, err := h.DB().FindInscribeOrderPayments(order.OrderId)
+	if err != nil {
+		return err
+	}
+	for _, payment := range payments {
+		resp.Paid += payment.Value
+	}
+	resp.Required = order.RequiredValue(len(payments))
+	if resp.Paid < resp.Required {
+		if len(payments) > 0 {
+			resp.Required = order.RequiredValue(len(payments) + 1)
+		}
+		resp.Missing = resp.Required - resp.Paid
+	}
+
+	items, err := h.DB().FindInscribeOrderItems(order.OrderId)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		inscriptionId := ""
+		if item.TxId != "" {
+			inscriptionId = item.InscriptionId.String()
+		}
+		resp.Inscriptions = append(resp.Inscriptions, &OrderInscriptionResp{
+			Index:          item.Index,
+			InscriptionId:  inscriptionId,
+			ContentType:    item.ContentType,
+			ReceiveAddress: item.ReceiveAddress,
+		})
 	}
 	ctx.JSON(http.StatusOK, resp)
 	return nil
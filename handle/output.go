@@ -0,0 +1,117 @@
+package handle
+
+import (
+	"encoding/hex"
+	"errors"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/cins/pkg/util"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/model"
+	"net/http"
+	"strings"
+)
+
+type OutputResp struct {
+	Output       string               `json:"output"`
+	Value        int64                `json:"value"`
+	ScriptPubKey string               `json:"script_pubkey"`
+	Address      string               `json:"address"`
+	Spent        bool                 `json:"spent"`
+	Inscriptions []*OutputInscription `json:"inscriptions"`
+}
+
+type OutputInscription struct {
+	InscriptionId     string `json:"inscription_id"`
+	InscriptionNumber int64  `json:"inscription_number"`
+	ContentType       string `json:"content_type"`
+	Offset            uint64 `json:"offset"`
+	SatPoint          string `json:"satpoint"`
+}
+
+// Output return the inscriptions sitting in an output with their offsets,
+// together with the value, script and spent state of the output from the node.
+func (h *Handler) Output(ctx *gin.Context) {
+	outpoint := model.StringToOutpoint(strings.TrimSpace(ctx.Param("output")))
+	if outpoint == nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "output is invalid"))
+		return
+	}
+	if err := h.doOutput(ctx, outpoint); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doOutput(ctx *gin.Context, outpoint *model.OutPoint) error {
+	list, err := h.IndexerDB().GetInscriptionByOutpoint(outpoint)
+	if err != nil {
+		return err
+	}
+
+	resp := &OutputResp{
+		Output:       outpoint.String(),
+		Inscriptions: make([]*OutputInscription, 0, len(list)),
+	}
+	for _, ins := range list {
+		resp.Inscriptions = append(resp.Inscriptions, &OutputInscription{
+			InscriptionId:     ins.InscriptionId.String(),
+			InscriptionNumber: ins.InscriptionNum,
+			ContentType:       ins.ContentType,
+			Offset:            ins.SatPointToSequenceNum.Offset,
+			SatPoint:          ins.SatPointToSequenceNum.String(),
+		})
+	}
+
+	// gettxout only knows unspent outputs, a spent output is looked up from its transaction
+	var pkScript []byte
+	txOut, err := h.RpcClient().GetTxOut(&outpoint.Hash, outpoint.Index, true)
+	if err != nil {
+		return err
+	}
+	if txOut != nil {
+		value, err := btcutil.NewAmount(txOut.Value)
+		if err != nil {
+			return err
+		}
+		resp.Value = int64(value)
+		pkScript, err = hex.DecodeString(txOut.ScriptPubKey.Hex)
+		if err != nil {
+			return err
+		}
+	} else {
+		tx, err := h.RpcClient().GetRawTransaction(&outpoint.Hash)
+		var jsonErr *btcjson.RPCError
+		if errors.As(err, &jsonErr) && jsonErr.Code == btcjson.ErrRPCNoTxInfo {
+			// without a transaction index the node can't return a spent transaction,
+			// the output is only known if the indexer has seen it
+			if len(resp.Inscriptions) == 0 {
+				ctx.Status(http.StatusNotFound)
+				return nil
+			}
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+		if tx != nil {
+			if int(outpoint.Index) >= len(tx.MsgTx().TxOut) {
+				ctx.Status(http.StatusNotFound)
+				return nil
+			}
+			out := tx.MsgTx().TxOut[outpoint.Index]
+			resp.Value = out.Value
+			pkScript = out.PkScript
+		}
+		resp.Spent = true
+	}
+
+	resp.ScriptPubKey = hex.EncodeToString(pkScript)
+	if _, addresses, _, err := txscript.ExtractPkScriptAddrs(pkScript, util.ActiveNet.Params); err == nil && len(addresses) > 0 {
+		resp.Address = addresses[0].EncodeAddress()
+	}
+	ctx.JSON(http.StatusOK, resp)
+	return nil
+}
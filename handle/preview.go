@@ -0,0 +1,191 @@
+package handle
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/explorer-api/config"
+	"github.com/inscription-c/explorer-api/constants"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/tables"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// previewTemplate renders every media type, the renderer field selects which block is used.
+var previewTemplate = template.Must(template.New("preview").Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width,initial-scale=1.0">
+<title>Inscription {{.InscriptionId}}</title>
+<style>
+html,body{margin:0;padding:0;width:100%;height:100%;background:#000;color:#fff;overflow:hidden}
+body{display:flex;align-items:center;justify-content:center}
+img,video,object,iframe,model-viewer{width:100%;height:100%;object-fit:contain;border:0}
+img{image-rendering:pixelated}
+pre{width:100%;height:100%;margin:0;padding:1em;box-sizing:border-box;overflow:auto;white-space:pre-wrap;word-break:break-all;font-family:monospace}
+{{- if eq .Renderer "font"}}
+@font-face{font-family:inscription;src:url("{{.ContentUrl}}")}
+p{font-family:inscription;font-size:2em;padding:1em;word-break:break-all}
+{{- end}}
+</style>
+</head>
+<body>
+{{- if eq .Renderer "image"}}
+<img src="{{.ContentUrl}}" alt="">
+{{- else if eq .Renderer "iframe"}}
+<iframe sandbox="allow-scripts" src="{{.ContentUrl}}" loading="lazy"></iframe>
+{{- else if eq .Renderer "model"}}
+<script type="module" src="{{.ModelViewerUrl}}"></script>
+<model-viewer src="{{.ContentUrl}}" auto-rotate camera-controls shadow-intensity="1"></model-viewer>
+{{- else if eq .Renderer "video"}}
+<video controls loop muted autoplay playsinline><source src="{{.ContentUrl}}" type="{{.ContentType}}"></video>
+{{- else if eq .Renderer "audio"}}
+<audio controls><source src="{{.ContentUrl}}" type="{{.ContentType}}"></audio>
+{{- else if eq .Renderer "pdf"}}
+<object data="{{.ContentUrl}}" type="{{.ContentType}}"></object>
+{{- else if eq .Renderer "font"}}
+<p>ABCDEFGHIJKLMNOPQRSTUVWXYZ abcdefghijklmnopqrstuvwxyz 0123456789</p>
+{{- else if eq .Renderer "text"}}
+<pre>{{.Text}}</pre>
+{{- else}}
+<a href="{{.ContentUrl}}" download>{{.ContentType}}</a>
+{{- end}}
+</body>
+</html>
+`))
+
+// ModelViewerPath is where the model-viewer script of the model previews is served.
+const ModelViewerPath = "/static/model-viewer.min.js"
+
+type previewData struct {
+	InscriptionId  string
+	Renderer       constants.MediaType
+	ContentUrl     string
+	ContentType    string
+	Text           string
+	ModelViewerUrl string
+}
+
+// Preview return a minimal html page that renders the inscription by its media type.
+// The page is served with a strict content security policy which only allows
+// loading same-origin /content and /r/ resources, and the model-viewer script for models.
+func (h *Handler) Preview(ctx *gin.Context) {
+	inscriptionId := strings.TrimSpace(ctx.Param("inscription_id"))
+	if !constants.InscriptionIdRegexp.MatchString(inscriptionId) {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "inscription_id is invalid"))
+		return
+	}
+	if err := h.doPreview(ctx, inscriptionId); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doPreview(ctx *gin.Context, inscriptionId string) error {
+	ins, err := h.IndexerDB().GetInscriptionById(tables.StringToInscriptionId(inscriptionId))
+	if err != nil {
+		return err
+	}
+	if ins.Id == 0 {
+		ctx.Status(http.StatusNotFound)
+		return nil
+	}
+
+	data := &previewData{
+		InscriptionId:  ins.InscriptionId.String(),
+		Renderer:       previewRenderer(constants.ContentType(ins.ContentType)),
+		ContentUrl:     "/content/" + ins.InscriptionId.String(),
+		ContentType:    ins.ContentType,
+		ModelViewerUrl: ModelViewerPath,
+	}
+
+	switch data.Renderer {
+	case constants.MediaText, constants.MediaMarkdown, constants.MediaJson:
+		body := ins.Body
+		if ins.ContentEncoding == contentEncodingBrotli {
+			body, err = decodeBrotli(ins.Body)
+			if err != nil {
+				return err
+			}
+		}
+		if data.Renderer == constants.MediaJson {
+			buf := bytes.NewBufferString("")
+			if json.Indent(buf, body, "", "  ") == nil {
+				body = buf.Bytes()
+			}
+		}
+		data.Renderer = constants.MediaText
+		data.Text = string(body)
+	}
+
+	buf := bytes.NewBufferString("")
+	if err := previewTemplate.Execute(buf, data); err != nil {
+		return err
+	}
+
+	content := sameOriginSources("/content/")
+	policy := []string{
+		"default-src 'none'",
+		"style-src 'unsafe-inline'",
+		fmt.Sprintf("img-src %s data: blob:", sameOriginSources("/content/", "/r/")),
+		"media-src " + content,
+		"font-src " + content,
+		"object-src " + content,
+		"frame-src " + content,
+		"base-uri 'none'",
+		"form-action 'none'",
+	}
+	// model-viewer is a same-origin module script that fetches the model and its textures
+	if data.Renderer == constants.MediaModel {
+		policy = append(policy,
+			"script-src 'self'",
+			fmt.Sprintf("connect-src %s data: blob:", sameOriginSources("/content/", "/r/")),
+		)
+	}
+	ctx.Header("Content-Security-Policy", strings.Join(policy, "; "))
+	ctx.Header("Cache-Control", "public, max-age=1209600, immutable")
+	ctx.Data(http.StatusOK, constants.ContentTypeTextHtmlUtf8.String(), buf.Bytes())
+	return nil
+}
+
+// previewRenderer maps a content type to the renderer of the preview page by its media type,
+// source code like media types are shown as plain text. Models are rendered by model-viewer if its
+// script is served, which doesn't render stl models.
+func previewRenderer(contentType constants.ContentType) constants.MediaType {
+	mediaType := contentType.MediaType()
+	switch mediaType {
+	case constants.MediaCss, constants.MediaJavaScript, constants.MediaPython, constants.MediaYaml:
+		return constants.MediaText
+	case constants.MediaModel:
+		if config.Cfg.Server.ModelViewerFile == "" || contentType == constants.ContentTypeModelStl {
+			return constants.MediaUnknown
+		}
+	}
+	return mediaType
+}
+
+// contentSecurityPolicy is the policy of the raw content, html and svg inscriptions
+// are sandboxed and can only load same-origin /content and /r/ resources.
+func contentSecurityPolicy() string {
+	return fmt.Sprintf("default-src %s 'unsafe-eval' 'unsafe-inline' data: blob:; sandbox allow-scripts",
+		sameOriginSources("/content/", "/r/"))
+}
+
+// sameOriginSources return the sources of the paths under the configured public url of the explorer.
+// The policies are cached by shared caches, so they are never built from the request headers.
+// Without a public url the sources can't be limited to the paths and 'self' is used instead.
+func sameOriginSources(paths ...string) string {
+	publicUrl := strings.TrimRight(config.Cfg.Server.PublicUrl, "/")
+	if publicUrl == "" {
+		return "'self'"
+	}
+	sources := make([]string, 0, len(paths))
+	for _, path := range paths {
+		sources = append(sources, publicUrl+path)
+	}
+	return strings.Join(sources, " ")
+}
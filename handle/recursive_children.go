@@ -0,0 +1,67 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/model"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const recursiveChildrenPageSize = 100
+
+type RecursiveChildrenResp struct {
+	model.PageResponse
+	Ids []string `json:"ids"`
+}
+
+// RecursiveChildren return a page of the children ids of an inscription
+func (h *Handler) RecursiveChildren(ctx *gin.Context) {
+	inscriptionId := strings.TrimSpace(ctx.Param("inscription_id"))
+	if inscriptionId == "" {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "inscription_id is required"))
+		return
+	}
+	page := 0
+	if pageStr := ctx.Param("page"); pageStr != "" {
+		var err error
+		page, err = strconv.Atoi(pageStr)
+		if err != nil || page < 0 {
+			ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "page is invalid"))
+			return
+		}
+	}
+	if err := h.doRecursiveChildren(ctx, inscriptionId, page); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+// doRecursiveChildren pages are zero-based to be compatible with ord recursive endpoints.
+func (h *Handler) doRecursiveChildren(ctx *gin.Context, inscriptionId string, page int) error {
+	ins, ok, err := h.findInscription(ctx, inscriptionId, SearchTypeInscriptionId)
+	if err != nil || !ok {
+		return err
+	}
+	list, err := h.DB().FindInscriptionChildren(ins.InscriptionId.String(), page+1, recursiveChildrenPageSize)
+	if err != nil {
+		return err
+	}
+
+	resp := &RecursiveChildrenResp{
+		PageResponse: model.PageResponse{
+			PageIndex: page,
+			More:      len(list) > recursiveChildrenPageSize,
+		},
+		Ids: make([]string, 0, len(list)),
+	}
+	if resp.More {
+		list = list[:recursiveChildrenPageSize]
+	}
+	for _, child := range list {
+		resp.Ids = append(resp.Ids, child.InscriptionId.String())
+	}
+	ctx.JSON(http.StatusOK, resp)
+	return nil
+}
@@ -0,0 +1,84 @@
+package handle
+
+import (
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/cins/inscription/index"
+	"github.com/inscription-c/explorer-api/constants"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/model"
+	"net/http"
+	"strings"
+)
+
+type RecursiveInscriptionResp struct {
+	Charms        []string `json:"charms"`
+	ContentType   string   `json:"content_type"`
+	ContentLength uint32   `json:"content_length"`
+	Fee           uint64   `json:"fee"`
+	Height        uint32   `json:"height"`
+	Id            string   `json:"id"`
+	Number        int64    `json:"number"`
+	Output        string   `json:"output"`
+	Sat           uint64   `json:"sat"`
+	SatPoint      string   `json:"satpoint"`
+	Timestamp     int64    `json:"timestamp"`
+	Value         int64    `json:"value"`
+}
+
+// RecursiveInscription return the json summary of an inscription
+func (h *Handler) RecursiveInscription(ctx *gin.Context) {
+	inscriptionId := strings.TrimSpace(ctx.Param("inscription_id"))
+	if !constants.InscriptionIdRegexp.MatchString(inscriptionId) {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "inscription_id is invalid"))
+		return
+	}
+	if err := h.doRecursiveInscription(ctx, inscriptionId); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doRecursiveInscription(ctx *gin.Context, inscriptionId string) error {
+	ins, ok, err := h.findInscription(ctx, inscriptionId, SearchTypeInscriptionId)
+	if err != nil || !ok {
+		return err
+	}
+
+	satPoint, output, err := h.inscriptionLocation(&ins)
+	if err != nil {
+		return err
+	}
+
+	resp := &RecursiveInscriptionResp{
+		Charms:        index.CharmsAll.Titles(ins.Charms),
+		ContentType:   ins.ContentType,
+		ContentLength: ins.ContentSize,
+		Fee:           ins.Fee,
+		Height:        ins.Height,
+		Id:            ins.InscriptionId.String(),
+		Number:        ins.InscriptionNum,
+		Output:        output,
+		Sat:           ins.Sat,
+		SatPoint:      satPoint,
+		Timestamp:     ins.Timestamp,
+	}
+
+	outpoint := model.StringToOutpoint(output)
+	if outpoint != nil {
+		txOut, err := h.RpcClient().GetTxOut(&outpoint.Hash, outpoint.Index, true)
+		if err != nil {
+			return err
+		}
+		if txOut != nil {
+			value, err := btcutil.NewAmount(txOut.Value)
+			if err != nil {
+				return err
+			}
+			resp.Value = int64(value)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+	return nil
+}
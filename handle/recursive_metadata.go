@@ -0,0 +1,37 @@
+package handle
+
+import (
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/explorer-api/constants"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/tables"
+	"net/http"
+	"strings"
+)
+
+// RecursiveMetadata return the hex encoded CBOR metadata of an inscription
+func (h *Handler) RecursiveMetadata(ctx *gin.Context) {
+	inscriptionId := strings.TrimSpace(ctx.Param("inscription_id"))
+	if !constants.InscriptionIdRegexp.MatchString(inscriptionId) {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "inscription_id is invalid"))
+		return
+	}
+	if err := h.doRecursiveMetadata(ctx, inscriptionId); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doRecursiveMetadata(ctx *gin.Context, inscriptionId string) error {
+	ins, err := h.IndexerDB().GetInscriptionById(tables.StringToInscriptionId(inscriptionId))
+	if err != nil {
+		return err
+	}
+	if ins.Id == 0 || len(ins.Metadata) == 0 {
+		ctx.Status(http.StatusNotFound)
+		return nil
+	}
+	ctx.JSON(http.StatusOK, hex.EncodeToString(ins.Metadata))
+	return nil
+}
@@ -0,0 +1,67 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/model"
+	"github.com/inscription-c/explorer-api/tables"
+	"net/http"
+	"strconv"
+)
+
+const recursiveSatPageSize = 100
+
+type RecursiveSatResp struct {
+	model.PageResponse
+	Ids []string `json:"ids"`
+}
+
+// RecursiveSat return a page of inscription ids on a sat
+func (h *Handler) RecursiveSat(ctx *gin.Context) {
+	sat, err := strconv.ParseUint(ctx.Param("sat"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "sat is invalid"))
+		return
+	}
+	page := 0
+	if pageStr := ctx.Param("page"); pageStr != "" {
+		page, err = strconv.Atoi(pageStr)
+		if err != nil || page < 0 {
+			ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "page is invalid"))
+			return
+		}
+	}
+	if err := h.doRecursiveSat(ctx, sat, page); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+// doRecursiveSat pages are zero-based to be compatible with ord recursive endpoints.
+// The inscriptions the indexer leaves on sat 0 have no sat, so none is returned for it.
+func (h *Handler) doRecursiveSat(ctx *gin.Context, sat uint64, page int) error {
+	list := make([]*tables.InscriptionId, 0)
+	if sat != 0 {
+		var err error
+		list, err = h.IndexerDB().FindInscriptionsBySat(sat, page+1, recursiveSatPageSize)
+		if err != nil {
+			return err
+		}
+	}
+
+	resp := &RecursiveSatResp{
+		PageResponse: model.PageResponse{
+			PageIndex: page,
+			More:      len(list) > recursiveSatPageSize,
+		},
+		Ids: make([]string, 0, len(list)),
+	}
+	if resp.More {
+		list = list[:recursiveSatPageSize]
+	}
+	for _, insId := range list {
+		resp.Ids = append(resp.Ids, insId.String())
+	}
+	ctx.JSON(http.StatusOK, resp)
+	return nil
+}
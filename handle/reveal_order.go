@@ -0,0 +1,269 @@
+package handle
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/cins/constants"
+	"github.com/inscription-c/cins/inscription"
+	"github.com/inscription-c/cins/pkg/util/txscript"
+	"github.com/inscription-c/explorer-api/dao"
+	"github.com/inscription-c/explorer-api/tables"
+	"net/url"
+)
+
+// scriptChunkSize is the max size of a data push in the reveal script.
+const scriptChunkSize = 520
+
+// cursedNotice is returned with the orders of many inscriptions. Every envelope after the first one is not at
+// envelope offset 0 and has a pointer, the indexer curses both until it has the jubilee rules, so those
+// inscriptions get negative inscription numbers and the cursed charm.
+const cursedNotice = "the inscriptions after the first one are cursed by the indexer: " +
+	"they get negative inscription numbers and the cursed charm"
+
+// multiInscriptionResponse adds the number of cursed inscriptions and the notice to the response of an order
+// of count inscriptions.
+func multiInscriptionResponse(res gin.H, count int) gin.H {
+	res["count"] = count
+	if count > 1 {
+		res["cursed"] = count - 1
+		res["notice"] = cursedNotice
+	}
+	return res
+}
+
+// revealEnvelope is an inscription envelope of a reveal script, empty fields are not written.
+type revealEnvelope struct {
+	CInsDescription *tables.CInsDescription
+	ContentType     constants.ContentType
+	Pointer         string
+	Metadata        []byte
+	ContentEncoding string
+	Body            []byte
+}
+
+// revealScript builds a reveal script that checks the signature of the internal key and holds the envelopes.
+// The fields are written in the same order as inscription.InscriptionToScript, which doesn't write the pointer
+// of its Header, so the pointer field is added here.
+func revealScript(internalKey *btcec.PublicKey, envelopes []*revealEnvelope) ([]byte, error) {
+	scriptBuilder := txscript.NewScriptBuilder()
+	scriptBuilder.AddData(schnorr.SerializePubKey(internalKey))
+	scriptBuilder.AddOp(txscript.OP_CHECKSIG)
+
+	for _, envelope := range envelopes {
+		scriptBuilder.
+			AddOp(txscript.OP_FALSE).
+			AddOp(txscript.OP_IF).
+			AddData([]byte(constants.ProtocolId))
+		if envelope.CInsDescription != nil {
+			scriptBuilder.
+				AddData([]byte(constants.CInsDescription)).
+				AddData(envelope.CInsDescription.Data())
+		}
+		if envelope.ContentType != "" {
+			scriptBuilder.
+				AddOp(txscript.OP_1).
+				AddData(envelope.ContentType.Bytes())
+		}
+		if envelope.Pointer != "" {
+			scriptBuilder.
+				AddOp(txscript.OP_2).
+				AddData([]byte(envelope.Pointer))
+		}
+		for _, chunk := range scriptChunks(envelope.Metadata) {
+			scriptBuilder.AddOp(txscript.OP_5)
+			scriptBuilder.AddData(chunk)
+		}
+		if envelope.ContentEncoding != "" {
+			scriptBuilder.AddOp(txscript.OP_9)
+			scriptBuilder.AddData([]byte(envelope.ContentEncoding))
+		}
+		if len(envelope.Body) > 0 {
+			scriptBuilder.AddOp(txscript.OP_0)
+			for _, chunk := range scriptChunks(envelope.Body) {
+				scriptBuilder.AddData(chunk)
+			}
+		}
+		scriptBuilder.AddOp(txscript.OP_ENDIF)
+	}
+	return scriptBuilder.Script()
+}
+
+func scriptChunks(data []byte) [][]byte {
+	chunks := make([][]byte, 0, len(data)/scriptChunkSize+1)
+	for start := 0; start < len(data); start += scriptChunkSize {
+		end := start + scriptChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		chunks = append(chunks, data[start:end])
+	}
+	return chunks
+}
+
+// revealKey is the internal key of the reveal script of an order, path is set if it's derived from the HD key.
+type revealKey struct {
+	*btcec.PrivateKey
+	path string
+}
+
+// newRevealKey derives a new reveal key from the HD key, or generates a random one if there's no HD key.
+func (h *Handler) newRevealKey() (*revealKey, error) {
+	if !h.HDKey().Enabled() {
+		priKey, err := btcec.NewPrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		return &revealKey{PrivateKey: priKey}, nil
+	}
+	index, err := h.DB().NextHDKeyIndex()
+	if err != nil {
+		return nil, err
+	}
+	path, err := h.HDKey().Path(index)
+	if err != nil {
+		return nil, err
+	}
+	priKey, err := h.HDKey().Derive(path)
+	if err != nil {
+		return nil, err
+	}
+	return &revealKey{PrivateKey: priKey, path: path}, nil
+}
+
+// newRevealOrder builds the unsigned reveal transaction that spends the commit output locked by the reveal script
+// and pays to the given outputs. The returned order asks for the outputs value plus the reveal fee,
+// the runner signs the reveal transaction once the commit transaction is found. Only the path of a derived key is stored,
+// a random key is stored encrypted by the keyring of the handler.
+func (h *Handler) newRevealOrder(priKey *revealKey, revealScript []byte, txOuts []*wire.TxOut, feeRate int64, receiveAddress string) (*tables.InscribeOrder, error) {
+	internalKey := priKey.PubKey()
+
+	// Generate the script address
+	controlBlock, taprootAddress, err := inscription.RevealScriptAddress(internalKey, revealScript)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the witness for the transaction
+	revealTxWitness := make([][]byte, 0)
+	revealTxWitness = append(revealTxWitness, make([]byte, 64))
+	revealTxWitness = append(revealTxWitness, revealScript)
+	controlBlockBytes, err := controlBlock.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	revealTxWitness = append(revealTxWitness, controlBlockBytes)
+	taprootScript, err := txscript.PayToAddrScript(taprootAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the transaction input
+	revealTxIn := &wire.TxIn{
+		SignatureScript: taprootScript,
+		Witness:         revealTxWitness,
+		Sequence:        0xFFFFFFFD,
+	}
+
+	// Create the reveal transaction
+	revealTx := wire.NewMsgTx(2)
+	revealTx.AddTxIn(revealTxIn)
+	outputsValue := int64(0)
+	for _, txOut := range txOuts {
+		revealTx.AddTxOut(txOut)
+		outputsValue += txOut.Value
+	}
+
+	revealTxRaw := bytes.NewBufferString("")
+	if err := revealTx.Serialize(revealTxRaw); err != nil {
+		return nil, err
+	}
+
+	revealPriKey := ""
+	if priKey.path == "" {
+		if revealPriKey, err = h.Keyring().Encrypt(priKey.Serialize()); err != nil {
+			return nil, err
+		}
+	}
+
+	txFee := inscription.CalculateTxFee(revealTx, feeRate)
+	order := &tables.InscribeOrder{
+		RevealAddress:  taprootAddress.String(),
+		RevealPriKey:   revealPriKey,
+		RevealKeyPath:  priKey.path,
+		RevealTxRaw:    hex.EncodeToString(revealTxRaw.Bytes()),
+		RevealTxValue:  txFee + outputsValue,
+		FeeRate:        feeRate,
+		ReceiveAddress: receiveAddress,
+	}
+	order.InitOrderId()
+	return order, nil
+}
+
+// createInscribeOrder saves a new order with the inscriptions of a batch order, and records the created event.
+func (h *Handler) createInscribeOrder(order *tables.InscribeOrder, items []*tables.InscribeOrderItem) error {
+	return h.DB().Transaction(func(tx *dao.DB) error {
+		if len(items) > 0 {
+			if err := tx.CreateBatchInscribeOrder(order, items); err != nil {
+				return err
+			}
+		} else if err := tx.CreateInscribeOrder(order); err != nil {
+			return err
+		}
+		return tx.CreateInscribeOrderEvent(&tables.InscribeOrderEvent{
+			OrderId: order.OrderId,
+			Type:    tables.OrderEventCreated,
+			Status:  order.Status,
+			Amount:  order.RevealTxValue,
+		})
+	})
+}
+
+// checkCallbackUrl return why a callback url is refused, or an empty string if there is no callback url or it's valid.
+// A callback url is an absolute http or https url that fits the callback url column.
+func checkCallbackUrl(callbackUrl string) string {
+	if callbackUrl == "" {
+		return ""
+	}
+	u, err := url.Parse(callbackUrl)
+	if err != nil || len(callbackUrl) > 1024 || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "invalid callback_url"
+	}
+	return ""
+}
+
+// callbackSecretSize is the number of random bytes of a callback secret.
+const callbackSecretSize = 32
+
+// setCallback sets the callback url of an order and a new secret the callbacks are signed by, the secret is
+// stored encrypted like the reveal keys. It returns the hex secret, which is only returned once by the create
+// response, or an empty string without a callback url.
+func (h *Handler) setCallback(order *tables.InscribeOrder, callbackUrl string) (string, error) {
+	if callbackUrl == "" {
+		return "", nil
+	}
+	secretBytes := make([]byte, callbackSecretSize)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", err
+	}
+	secret := hex.EncodeToString(secretBytes)
+	callbackSecret, err := h.Keyring().Encrypt([]byte(secret))
+	if err != nil {
+		return "", err
+	}
+	order.CallbackUrl = callbackUrl
+	order.CallbackSecret = callbackSecret
+	return secret, nil
+}
+
+// callbackResponse adds the callback secret to the create response of an order with a callback url.
+func callbackResponse(res gin.H, secret string) gin.H {
+	if secret != "" {
+		res["callback_secret"] = secret
+	}
+	return res
+}
@@ -8,6 +8,9 @@ import (
 	"github.com/inscription-c/explorer-api/handle/middlewares"
 )
 
+// publicPrefixes are the paths readable by any origin, recursive html inscriptions fetch them from a sandbox.
+var publicPrefixes = []string{"/content/", "/r/"}
+
 func (h *Handler) InitRouter() {
 	h.Engine().Use(gin.Recovery())
 	if config.Cfg.Server.EnablePProf {
@@ -18,7 +21,7 @@ func (h *Handler) InitRouter() {
 		p.Use(h.Engine())
 	}
 
-	h.Engine().Use(middlewares.Cors(config.Cfg.Origins...))
+	h.Engine().Use(middlewares.Cors(publicPrefixes, config.Cfg.Origins...))
 	if config.Cfg.Sentry.Dsn != "" {
 		h.Engine().Use(sentrygin.New(sentrygin.Options{
 			Repanic: true,
@@ -27,13 +30,54 @@ func (h *Handler) InitRouter() {
 	h.Engine().Use(middlewares.Logger())
 	h.Engine().GET("/home/page/statistics", h.HomePageStatistics)
 	h.Engine().POST("/inscriptions", h.Inscriptions)
+	h.Engine().GET("/inscription/:id", h.Inscription)
+	h.Engine().GET("/inscription/:id/metadata", h.InscriptionMetadata)
+	h.Engine().GET("/content/:inscription_id", h.Content)
+	h.Engine().GET("/preview/:inscription_id", h.Preview)
+	if config.Cfg.Server.ModelViewerFile != "" {
+		h.Engine().StaticFile(ModelViewerPath, config.Cfg.Server.ModelViewerFile)
+	}
+	h.Engine().GET("/blocks", h.Blocks)
+	h.Engine().GET("/block/:height_or_hash", h.Block)
+	h.Engine().GET("/block/:height_or_hash/inscriptions", h.BlockInscriptions)
+	h.Engine().GET("/output/:output", h.Output)
+	h.Engine().GET("/sat/:sat", h.Sat)
+	h.Engine().GET("/address/:address", h.Address)
+	h.Engine().GET("/cbrc20/tokens", h.CBRC20Tokens)
+	h.Engine().GET("/cbrc20/token/:ticker", h.CBRC20Token)
+	h.Engine().GET("/cbrc20/token/:ticker/holders", h.CBRC20TokenHolders)
+	h.Engine().GET("/cbrc20/token/:ticker/activity", h.CBRC20TokenActivity)
 
 	r := h.Engine().Group("/r")
 	r.GET("/blockheight", h.BlockHeight)
+	r.GET("/blockhash", h.BlockHash)
+	r.GET("/blockhash/:height", h.BlockHash)
+	r.GET("/blocktime", h.BlockTime)
+	r.GET("/metadata/:inscription_id", h.RecursiveMetadata)
+	r.GET("/sat/:sat", h.RecursiveSat)
+	r.GET("/sat/:sat/:page", h.RecursiveSat)
+	r.GET("/inscription/:inscription_id", h.RecursiveInscription)
+	r.GET("/children/:inscription_id", h.RecursiveChildren)
+	r.GET("/children/:inscription_id/:page", h.RecursiveChildren)
 
 	h.Engine().GET("/l2/networks", h.L2Networks)
 	h.Engine().GET("/estimate-smart-fee", h.EstimateSmartFee)
 	h.Engine().GET("/order/status/:order_id", h.OrderStatus)
+	h.Engine().GET("/stream/ws", h.StreamWebSocket)
+	h.Engine().GET("/stream/sse", h.StreamSSE)
+	h.Engine().GET("/order/:order_id/events", h.OrderEvents)
 	h.Engine().GET("/inscribe/orders/:receive_address/:page", h.InscribeOrders)
 	h.Engine().POST("/inscribe/order/create/c-brc20-deploy", h.CreateCbr20DeployOrder)
+	h.Engine().POST("/inscribe/order/create/c-brc20-mint", h.CreateCbr20MintOrder)
+	h.Engine().POST("/inscribe/order/create/file", h.CreateFileOrder)
+	h.Engine().POST("/inscribe/order/create/batch", h.CreateBatchOrder)
+
+	if len(config.Cfg.Server.AdminAccounts) > 0 {
+		h.Engine().POST("/order/bump/:order_id", gin.BasicAuth(config.Cfg.Server.AdminAccounts), h.BumpOrder)
+	}
+	if len(config.Cfg.Webhook.Accounts) > 0 {
+		webhook := h.Engine().Group("/webhook", gin.BasicAuth(config.Cfg.Webhook.Accounts))
+		webhook.GET("/deliveries/failed", h.FailedWebhookDeliveries)
+		webhook.POST("/delivery/:id/replay", h.ReplayWebhookDelivery)
+	}
 }
@@ -0,0 +1,102 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/cins/inscription/index"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/model"
+	"net/http"
+)
+
+type SatReq struct {
+	Page  int `form:"page" binding:"omitempty,min=1"`
+	Limit int `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+func (req *SatReq) Check() error {
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 50
+	}
+	return nil
+}
+
+type SatResp struct {
+	model.PageResponse
+	Sat          uint64              `json:"sat"`
+	Decimal      string              `json:"decimal"`
+	Degree       string              `json:"degree"`
+	Name         string              `json:"name"`
+	Rarity       string              `json:"rarity"`
+	Epoch        uint32              `json:"epoch"`
+	Height       uint32              `json:"height"`
+	Cycle        uint32              `json:"cycle"`
+	Period       uint32              `json:"period"`
+	Offset       uint64              `json:"offset"`
+	Inscriptions []*InscriptionEntry `json:"inscriptions"`
+}
+
+// Sat return the notations, rarity and a page of inscriptions of a sat.
+// The sat can be in integer, decimal, degree, percentile or name notation.
+func (h *Handler) Sat(ctx *gin.Context) {
+	sat, err := model.StringToSat(ctx.Param("sat"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "sat is invalid"))
+		return
+	}
+	req := &SatReq{}
+	if err := ctx.ShouldBindQuery(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, err.Error()))
+		return
+	}
+	if err := req.Check(); err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, err.Error()))
+		return
+	}
+	if err := h.doSat(ctx, sat, req); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doSat(ctx *gin.Context, sat index.Sat, req *SatReq) error {
+	height := sat.Height().N()
+	resp := &SatResp{
+		PageResponse: model.PageResponse{
+			PageIndex: req.Page,
+		},
+		Sat:          sat.N(),
+		Decimal:      model.SatDecimal(sat),
+		Degree:       model.SatDegree(sat),
+		Name:         model.SatName(sat),
+		Rarity:       model.RarityName(sat.Rarity()),
+		Epoch:        model.SatEpoch(sat),
+		Height:       height,
+		Cycle:        model.SatCycle(sat),
+		Period:       height / index.DiffChangeInterval,
+		Offset:       sat.Third(),
+		Inscriptions: make([]*InscriptionEntry, 0),
+	}
+	// sat 0 is the unspendable genesis coinbase sat, the indexer leaves the sat of the unbound
+	// inscriptions and of all inscriptions without a sat index at 0, so none of them is on it.
+	if sat.N() == 0 {
+		ctx.JSON(http.StatusOK, resp)
+		return nil
+	}
+
+	list, err := h.IndexerDB().FindInscriptionsOnSat(sat.N(), req.Page, req.Limit)
+	if err != nil {
+		return err
+	}
+	resp.More = len(list) > req.Limit
+	if resp.More {
+		list = list[:req.Limit]
+	}
+	for _, ins := range list {
+		resp.Inscriptions = append(resp.Inscriptions, insToScanEntry(ins))
+	}
+	ctx.JSON(http.StatusOK, resp)
+	return nil
+}
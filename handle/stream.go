@@ -0,0 +1,204 @@
+package handle
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/inscription-c/explorer-api/config"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/stream"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// streamMaxTopics is the max number of addresses, tickers or order ids of a stream subscription.
+	streamMaxTopics = 100
+	// streamWriteTimeout is how long a write to a stream client may take before the client is dropped.
+	streamWriteTimeout = time.Second * 10
+	// streamPingInterval is how often a stream connection is kept alive when there are no events.
+	streamPingInterval = time.Second * 30
+)
+
+var streamUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		for _, v := range config.Cfg.Origins {
+			if ok, err := regexp.MatchString(v, origin); err == nil && ok {
+				return true
+			}
+		}
+		return false
+	},
+}
+
+type StreamReq struct {
+	Types     []string `form:"types"`
+	Addresses []string `form:"address"`
+	Tickers   []string `form:"ticker"`
+	OrderIds  []string `form:"order_id"`
+
+	filter *stream.Filter
+}
+
+// Check builds the filter of a stream subscription, every parameter is repeated or a comma separated list.
+func (req *StreamReq) Check() error {
+	invalidParams := api_code.NewResponse(api_code.InvalidParams, "")
+	req.filter = &stream.Filter{
+		Types:     make(map[stream.EventType]bool),
+		Addresses: streamTopics(req.Addresses),
+		Tickers:   streamTopics(req.Tickers),
+		OrderIds:  streamTopics(req.OrderIds),
+	}
+	for t := range streamTopics(req.Types) {
+		switch eventType := stream.EventType(t); eventType {
+		case stream.EventTypeBlock, stream.EventTypeInscription, stream.EventTypeOrder:
+			req.filter.Types[eventType] = true
+		default:
+			invalidParams.Message = fmt.Sprintf("invalid type %s", t)
+			return invalidParams
+		}
+	}
+	if len(req.filter.Addresses) > streamMaxTopics || len(req.filter.Tickers) > streamMaxTopics ||
+		len(req.filter.OrderIds) > streamMaxTopics {
+		invalidParams.Message = fmt.Sprintf("at most %d addresses, tickers or order ids", streamMaxTopics)
+		return invalidParams
+	}
+	// the order events are only sent to the subscribers of an order id or an address
+	if req.filter.Types[stream.EventTypeOrder] && len(req.filter.Addresses) == 0 && len(req.filter.OrderIds) == 0 {
+		invalidParams.Message = "order events need an order_id or address"
+		return invalidParams
+	}
+	return nil
+}
+
+func streamTopics(values []string) map[string]bool {
+	topics := make(map[string]bool)
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				topics[v] = true
+			}
+		}
+	}
+	return topics
+}
+
+// StreamWebSocket pushes the events of a subscription as JSON text messages over a WebSocket.
+// A client that doesn't keep up is closed with the policy violation code.
+func (h *Handler) StreamWebSocket(ctx *gin.Context) {
+	req := &StreamReq{}
+	if err := ctx.ShouldBindQuery(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, err.Error()))
+		return
+	}
+	if err := req.Check(); err != nil {
+		ctx.JSON(http.StatusBadRequest, err)
+		return
+	}
+	conn, err := streamUpgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	h.doStreamWebSocket(conn, req)
+}
+
+func (h *Handler) doStreamWebSocket(conn *websocket.Conn, req *StreamReq) {
+	sub := h.Stream().Subscribe(req.filter)
+	defer h.Stream().Unsubscribe(sub)
+
+	// the client sends nothing but control messages, reading detects a closed connection
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(streamPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-sub.Done():
+			msg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "slow consumer")
+			_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(streamWriteTimeout))
+			return
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout)); err != nil {
+				return
+			}
+		case event := <-sub.Events():
+			_ = conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// StreamSSE pushes the events of a subscription as server-sent events named by the event type.
+// A client that doesn't keep up gets an error event and the stream ends.
+func (h *Handler) StreamSSE(ctx *gin.Context) {
+	req := &StreamReq{}
+	if err := ctx.ShouldBindQuery(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, err.Error()))
+		return
+	}
+	if err := req.Check(); err != nil {
+		ctx.JSON(http.StatusBadRequest, err)
+		return
+	}
+	h.doStreamSSE(ctx, req)
+}
+
+func (h *Handler) doStreamSSE(ctx *gin.Context, req *StreamReq) {
+	sub := h.Stream().Subscribe(req.filter)
+	defer h.Stream().Unsubscribe(sub)
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	ping := time.NewTicker(streamPingInterval)
+	defer ping.Stop()
+	rc := http.NewResponseController(ctx.Writer)
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case <-sub.Done():
+			ctx.SSEvent("error", "slow consumer")
+			ctx.Writer.Flush()
+			return
+		case <-ping.C:
+			_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if _, err := ctx.Writer.WriteString(": ping\n\n"); err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+		case event := <-sub.Events():
+			data, err := json.Marshal(event.Data)
+			if err != nil {
+				return
+			}
+			_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			ctx.SSEvent(string(event.Type), string(data))
+			ctx.Writer.Flush()
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/explorer-api/handle/api_code"
+	"github.com/inscription-c/explorer-api/model"
+	"github.com/inscription-c/explorer-api/tables"
+	"net/http"
+	"strconv"
+)
+
+type FailedWebhookDeliveriesResp struct {
+	model.PageResponse
+	List []*WebhookDeliveryEntry `json:"list"`
+}
+
+type WebhookDeliveryEntry struct {
+	Id           uint64 `json:"id"`
+	OrderId      string `json:"order_id"`
+	Url          string `json:"url"`
+	Payload      string `json:"payload"`
+	Attempts     int    `json:"attempts"`
+	ResponseCode int    `json:"response_code"`
+	LastError    string `json:"last_error"`
+	CreatedAt    int64  `json:"created_at"`
+	UpdatedAt    int64  `json:"updated_at"`
+}
+
+// FailedWebhookDeliveries returns a page of the callbacks that failed all attempts, the latest first.
+func (h *Handler) FailedWebhookDeliveries(ctx *gin.Context) {
+	req := &BlockPageReq{}
+	if err := ctx.ShouldBindQuery(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, err.Error()))
+		return
+	}
+	if err := req.Check(); err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, err.Error()))
+		return
+	}
+	if err := h.doFailedWebhookDeliveries(ctx, req); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doFailedWebhookDeliveries(ctx *gin.Context, req *BlockPageReq) error {
+	list, err := h.DB().FindFailedWebhookDeliveries(req.Page, req.Limit)
+	if err != nil {
+		return err
+	}
+
+	resp := &FailedWebhookDeliveriesResp{
+		PageResponse: model.PageResponse{
+			PageIndex: req.Page,
+			More:      len(list) > req.Limit,
+		},
+		List: make([]*WebhookDeliveryEntry, 0, len(list)),
+	}
+	if resp.More {
+		list = list[:req.Limit]
+	}
+	for _, delivery := range list {
+		resp.List = append(resp.List, &WebhookDeliveryEntry{
+			Id:           delivery.Id,
+			OrderId:      delivery.OrderId,
+			Url:          delivery.Url,
+			Payload:      delivery.Payload,
+			Attempts:     delivery.Attempts,
+			ResponseCode: delivery.ResponseCode,
+			LastError:    delivery.LastError,
+			CreatedAt:    delivery.CreatedAt.Unix(),
+			UpdatedAt:    delivery.UpdatedAt.Unix(),
+		})
+	}
+	ctx.JSON(http.StatusOK, resp)
+	return nil
+}
+
+// ReplayWebhookDelivery queues a failed callback again, the runner attempts it again with a new set of retries.
+func (h *Handler) ReplayWebhookDelivery(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "invalid id"))
+		return
+	}
+	if err := h.doReplayWebhookDelivery(ctx, id); err != nil {
+		ctx.JSON(http.StatusInternalServerError, api_code.NewResponse(api_code.InternalServerErr, err.Error()))
+		return
+	}
+}
+
+func (h *Handler) doReplayWebhookDelivery(ctx *gin.Context, id uint64) error {
+	delivery, err := h.DB().GetWebhookDeliveryById(id)
+	if err != nil {
+		return err
+	}
+	if delivery.Id == 0 {
+		ctx.Status(http.StatusNotFound)
+		return nil
+	}
+	if delivery.Status != tables.WebhookDeliveryStatusFailed {
+		ctx.JSON(http.StatusBadRequest, api_code.NewResponse(api_code.InvalidParams, "delivery is not failed"))
+		return nil
+	}
+
+	if err := h.DB().ReplayWebhookDelivery(id); err != nil {
+		return err
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"id": delivery.Id,
+	})
+	return nil
+}
@@ -0,0 +1,98 @@
+package keystore
+
+import (
+	"errors"
+	"fmt"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MaxHDKeyIndex is the last index of the derived keys, the indexes are not hardened.
+const MaxHDKeyIndex = hdkeychain.HardenedKeyStart - 1
+
+// HDKey derives the reveal private keys of the orders from a BIP-32 master key by the path
+// m/86'/<coin type>'/0'/0/<index>, so that the keys are recovered from the master key and the paths.
+//
+// A nil HDKey derives nothing, the orders get random keys.
+type HDKey struct {
+	branch *hdkeychain.ExtendedKey
+	prefix string
+}
+
+// LoadHDKey loads an extended private key from a key file, or from an environment variable if there's no key file.
+// It return nil if there's neither of them.
+func LoadHDKey(keyFile, keyEnv string, coinType uint32) (*HDKey, error) {
+	var data string
+	if keyFile != "" {
+		bytes, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		data = string(bytes)
+	} else if keyEnv != "" {
+		data = os.Getenv(keyEnv)
+	}
+	data = strings.TrimSpace(data)
+	if data == "" {
+		return nil, nil
+	}
+
+	master, err := hdkeychain.NewKeyFromString(data)
+	if err != nil {
+		return nil, err
+	}
+	if !master.IsPrivate() {
+		return nil, errors.New("hd key is not an extended private key")
+	}
+	branch := master
+	for _, index := range []uint32{
+		hdkeychain.HardenedKeyStart + 86,
+		hdkeychain.HardenedKeyStart + coinType,
+		hdkeychain.HardenedKeyStart,
+		0,
+	} {
+		if branch, err = branch.Derive(index); err != nil {
+			return nil, err
+		}
+	}
+	return &HDKey{
+		branch: branch,
+		prefix: fmt.Sprintf("m/86'/%d'/0'/0/", coinType),
+	}, nil
+}
+
+// Enabled return whether there is a master key to derive the keys from.
+func (k *HDKey) Enabled() bool {
+	return k != nil
+}
+
+// Path return the derivation path of an index.
+func (k *HDKey) Path(index uint64) (string, error) {
+	if index > MaxHDKeyIndex {
+		return "", fmt.Errorf("hd key index %d is out of range", index)
+	}
+	return k.prefix + strconv.FormatUint(index, 10), nil
+}
+
+// Derive return the private key of a derivation path returned by Path.
+func (k *HDKey) Derive(path string) (*btcec.PrivateKey, error) {
+	if !k.Enabled() {
+		return nil, fmt.Errorf("no hd key to derive %s", path)
+	}
+	indexStr, ok := strings.CutPrefix(path, k.prefix)
+	if !ok {
+		return nil, fmt.Errorf("path %s is not under %s", path, k.prefix)
+	}
+	index, err := strconv.ParseUint(indexStr, 10, 32)
+	if err != nil || index > MaxHDKeyIndex {
+		return nil, fmt.Errorf("invalid path %s", path)
+	}
+	child, err := k.branch.Derive(uint32(index))
+	if err != nil {
+		return nil, err
+	}
+	return child.ECPrivKey()
+}
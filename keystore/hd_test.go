@@ -0,0 +1,103 @@
+package keystore
+
+import (
+	"encoding/hex"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"testing"
+)
+
+// testHDKey is the master key of the BIP-86 test vectors, of the mnemonic "abandon abandon ... about".
+const (
+	testHDKeyEnv = "TEST_HD_KEY"
+	testHDKey    = "xprv9s21ZrQH143K3GJpoapnV8SFfukcVBSfeCficPSGfubmSFDxo1kuHnLisriDvSnRRuL2Qrg5ggqHKNVpxR86QEC8w35uxmGoggxtQTPvfUu"
+)
+
+func testLoadHDKey(t *testing.T) *HDKey {
+	t.Helper()
+	t.Setenv(testHDKeyEnv, testHDKey)
+	k, err := LoadHDKey("", testHDKeyEnv, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestHDKeyDerive(t *testing.T) {
+	k := testLoadHDKey(t)
+	tests := []struct {
+		index       uint64
+		wantPath    string
+		internalKey string
+		address     string
+	}{
+		{
+			0, "m/86'/0'/0'/0/0",
+			"cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115",
+			"bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr",
+		},
+		{
+			1, "m/86'/0'/0'/0/1",
+			"83dfe85a3151d2517290da461fe2815591ef69f2b18a2ce63f01697a8b313145",
+			"bc1p4qhjn9zdvkux4e44uhx8tc55attvtyu358kutcqkudyccelu0was9fqzwh",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wantPath, func(t *testing.T) {
+			path, err := k.Path(tt.index)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if path != tt.wantPath {
+				t.Fatalf("path %s, want %s", path, tt.wantPath)
+			}
+			priKey, err := k.Derive(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			internalKey := hex.EncodeToString(schnorr.SerializePubKey(priKey.PubKey()))
+			if internalKey != tt.internalKey {
+				t.Fatalf("internal key %s, want %s", internalKey, tt.internalKey)
+			}
+			outputKey := txscript.ComputeTaprootKeyNoScript(priKey.PubKey())
+			address, err := btcutil.NewAddressTaproot(schnorr.SerializePubKey(outputKey), &chaincfg.MainNetParams)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if address.String() != tt.address {
+				t.Fatalf("address %s, want %s", address, tt.address)
+			}
+		})
+	}
+}
+
+func TestHDKeyInvalid(t *testing.T) {
+	k := testLoadHDKey(t)
+	if _, err := k.Path(MaxHDKeyIndex + 1); err == nil {
+		t.Fatal("path of an out of range index")
+	}
+	for _, path := range []string{
+		"m/86'/1'/0'/0/0",
+		"m/86'/0'/0'/0/x",
+		"m/86'/0'/0'/0/2147483648",
+		"m/86'/0'/0'/0/",
+	} {
+		if _, err := k.Derive(path); err == nil {
+			t.Fatalf("derived invalid path %s", path)
+		}
+	}
+
+	var disabled *HDKey
+	if disabled.Enabled() {
+		t.Fatal("nil hd key is enabled")
+	}
+	if _, err := disabled.Derive("m/86'/0'/0'/0/0"); err == nil {
+		t.Fatal("derived by a nil hd key")
+	}
+	loaded, err := LoadHDKey("", "", 0)
+	if err != nil || loaded != nil {
+		t.Fatalf("load without key = %v, %v, want nil", loaded, err)
+	}
+}
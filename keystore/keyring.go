@@ -0,0 +1,213 @@
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// KeySize is the size of the master keys and the data keys, they're AES-256 keys.
+	KeySize = 32
+	// encryptedPrefix marks an encrypted value, the values without it are plaintext hex.
+	encryptedPrefix = "enc"
+)
+
+var ErrUnknownKeyVersion = errors.New("unknown master key version")
+
+// Keyring holds the versioned master keys of the envelope encryption of the reveal private keys.
+// A value is encrypted by a random data key, and the data key is encrypted by the current master key,
+// so that a value is stored as "enc:<version>:<encrypted data key>:<encrypted value>".
+// The old master keys are kept to decrypt the values that are not re-encrypted yet.
+//
+// A nil or empty Keyring stores the values as plaintext hex.
+type Keyring struct {
+	keys    map[uint32][]byte
+	current uint32
+}
+
+// Load loads the master keys from a key file and an environment variable, either of them may be empty.
+// Both hold "<version>:<hex key>" entries separated by new lines or commas, the highest version is the current key.
+func Load(keyFile, keyEnv string) (*Keyring, error) {
+	k := &Keyring{keys: make(map[uint32][]byte)}
+	if keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := k.parse(string(data)); err != nil {
+			return nil, fmt.Errorf("key file %s: %w", keyFile, err)
+		}
+	}
+	if keyEnv != "" {
+		if err := k.parse(os.Getenv(keyEnv)); err != nil {
+			return nil, fmt.Errorf("env %s: %w", keyEnv, err)
+		}
+	}
+	return k, nil
+}
+
+func (k *Keyring) parse(data string) error {
+	entries := strings.FieldsFunc(data, func(r rune) bool {
+		return r == '\n' || r == '\r' || r == ','
+	})
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		versionStr, keyHex, ok := strings.Cut(entry, ":")
+		if !ok {
+			return errors.New("invalid entry, want <version>:<hex key>")
+		}
+		version, err := strconv.ParseUint(strings.TrimSpace(versionStr), 10, 32)
+		if err != nil || version == 0 {
+			return fmt.Errorf("invalid version %s", versionStr)
+		}
+		key, err := hex.DecodeString(strings.TrimSpace(keyHex))
+		if err != nil || len(key) != KeySize {
+			return fmt.Errorf("invalid key of version %d, want %d hex bytes", version, KeySize)
+		}
+		if old, ok := k.keys[uint32(version)]; ok && string(old) != string(key) {
+			return fmt.Errorf("conflicting keys of version %d", version)
+		}
+		k.keys[uint32(version)] = key
+		if uint32(version) > k.current {
+			k.current = uint32(version)
+		}
+	}
+	return nil
+}
+
+// Enabled return whether there is a master key, the values are stored as plaintext hex without it.
+func (k *Keyring) Enabled() bool {
+	return k != nil && k.current > 0
+}
+
+// Current return the version of the master key new values are encrypted by.
+func (k *Keyring) Current() uint32 {
+	if k == nil {
+		return 0
+	}
+	return k.current
+}
+
+// CurrentPrefix return the prefix of the values encrypted by the current master key.
+func (k *Keyring) CurrentPrefix() string {
+	return fmt.Sprintf("%s:%d:", encryptedPrefix, k.Current())
+}
+
+// Encrypt encrypts a value by the current master key.
+func (k *Keyring) Encrypt(plaintext []byte) (string, error) {
+	if !k.Enabled() {
+		return hex.EncodeToString(plaintext), nil
+	}
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	header := fmt.Sprintf("%s:%d", encryptedPrefix, k.current)
+	encryptedKey, err := seal(k.keys[k.current], dataKey, []byte(header))
+	if err != nil {
+		return "", err
+	}
+	encryptedValue, err := seal(dataKey, plaintext, []byte(header))
+	if err != nil {
+		return "", err
+	}
+	return strings.Join([]string{
+		header,
+		base64.RawStdEncoding.EncodeToString(encryptedKey),
+		base64.RawStdEncoding.EncodeToString(encryptedValue),
+	}, ":"), nil
+}
+
+// Decrypt decrypts a value encrypted by any of the master keys, a plaintext hex value is decoded as it is.
+func (k *Keyring) Decrypt(value string) ([]byte, error) {
+	version, encrypted := Version(value)
+	if !encrypted {
+		return hex.DecodeString(value)
+	}
+	if k == nil || k.keys[version] == nil {
+		return nil, fmt.Errorf("%w %d", ErrUnknownKeyVersion, version)
+	}
+	parts := strings.Split(value, ":")
+	if len(parts) != 4 {
+		return nil, errors.New("invalid encrypted value")
+	}
+	encryptedKey, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	encryptedValue, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return nil, err
+	}
+	header := []byte(strings.Join(parts[:2], ":"))
+	dataKey, err := open(k.keys[version], encryptedKey, header)
+	if err != nil {
+		return nil, err
+	}
+	return open(dataKey, encryptedValue, header)
+}
+
+// NeedsReencrypt return whether a value is plaintext or encrypted by an old master key.
+func (k *Keyring) NeedsReencrypt(value string) bool {
+	if !k.Enabled() {
+		return false
+	}
+	version, encrypted := Version(value)
+	return !encrypted || version != k.current
+}
+
+// Version return the version of the master key a value is encrypted by, and whether it's encrypted.
+func Version(value string) (uint32, bool) {
+	parts := strings.SplitN(value, ":", 3)
+	if len(parts) < 3 || parts[0] != encryptedPrefix {
+		return 0, false
+	}
+	version, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(version), true
+}
+
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(key, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("invalid encrypted value")
+	}
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,174 @@
+package keystore
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const (
+	testKey1 = "1:000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+	testKey2 = "2:202122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3f"
+)
+
+func testKeyring(t *testing.T, entries ...string) *Keyring {
+	t.Helper()
+	k := &Keyring{keys: make(map[uint32][]byte)}
+	if err := k.parse(strings.Join(entries, "\n")); err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestKeyringEncryptDecrypt(t *testing.T) {
+	plaintext := []byte("reveal private key")
+	tests := []struct {
+		name       string
+		keyring    *Keyring
+		wantPrefix string
+	}{
+		{"nil keyring", nil, ""},
+		{"empty keyring", testKeyring(t), ""},
+		{"one key", testKeyring(t, testKey1), "enc:1:"},
+		{"current key", testKeyring(t, testKey1, testKey2), "enc:2:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := tt.keyring.Encrypt(plaintext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantPrefix == "" {
+				if _, encrypted := Version(value); encrypted {
+					t.Fatalf("value %s is encrypted, want plaintext hex", value)
+				}
+			} else if !strings.HasPrefix(value, tt.wantPrefix) {
+				t.Fatalf("value %s, want prefix %s", value, tt.wantPrefix)
+			}
+			if tt.wantPrefix != "" && tt.keyring.CurrentPrefix() != tt.wantPrefix {
+				t.Fatalf("current prefix %s, want %s", tt.keyring.CurrentPrefix(), tt.wantPrefix)
+			}
+			got, err := tt.keyring.Decrypt(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, plaintext) {
+				t.Fatalf("decrypted %q, want %q", got, plaintext)
+			}
+			if tt.keyring.NeedsReencrypt(value) {
+				t.Fatal("new value needs re-encrypt")
+			}
+		})
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	plaintext := []byte("reveal private key")
+	old, err := testKeyring(t, testKey1).Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := testKeyring(t, testKey1, testKey2)
+	if !rotated.NeedsReencrypt(old) {
+		t.Fatal("value of an old key doesn't need re-encrypt")
+	}
+	got, err := rotated.Decrypt(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Fatalf("decrypted %q, want %q", got, plaintext)
+	}
+	value, err := rotated.Encrypt(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, _ := Version(value); version != 2 {
+		t.Fatalf("re-encrypted by version %d, want 2", version)
+	}
+
+	removed := testKeyring(t, testKey2)
+	if _, err := removed.Decrypt(old); !errors.Is(err, ErrUnknownKeyVersion) {
+		t.Fatalf("decrypt by a removed key err %v, want %v", err, ErrUnknownKeyVersion)
+	}
+}
+
+func TestKeyringPlaintext(t *testing.T) {
+	const value = "0a0b0c"
+	tests := []struct {
+		name          string
+		keyring       *Keyring
+		wantReencrypt bool
+	}{
+		{"nil keyring", nil, false},
+		{"empty keyring", testKeyring(t), false},
+		{"with key", testKeyring(t, testKey1), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.keyring.Decrypt(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, []byte{0x0a, 0x0b, 0x0c}) {
+				t.Fatalf("decrypted %x, want %s", got, value)
+			}
+			if tt.keyring.NeedsReencrypt(value) != tt.wantReencrypt {
+				t.Fatalf("needs re-encrypt %v, want %v", !tt.wantReencrypt, tt.wantReencrypt)
+			}
+		})
+	}
+}
+
+func TestKeyringWrongVersion(t *testing.T) {
+	value, err := testKeyring(t, testKey1).Encrypt([]byte("reveal private key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		keyring *Keyring
+		value   string
+		wantErr error
+	}{
+		{"nil keyring", nil, value, ErrUnknownKeyVersion},
+		{"missing version", testKeyring(t, testKey2), value, ErrUnknownKeyVersion},
+		{"swapped version", testKeyring(t, testKey1, testKey2), strings.Replace(value, "enc:1:", "enc:2:", 1), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.keyring.Decrypt(tt.value)
+			if err == nil {
+				t.Fatal("decrypted by a wrong key version")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVersion(t *testing.T) {
+	tests := []struct {
+		value         string
+		wantVersion   uint32
+		wantEncrypted bool
+	}{
+		{"enc:3:a:b", 3, true},
+		{"enc:x:a:b", 0, false},
+		{"enc:3", 0, false},
+		{"0a0b0c", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			version, encrypted := Version(tt.value)
+			if version != tt.wantVersion || encrypted != tt.wantEncrypted {
+				t.Fatalf("Version(%q) = %d, %v, want %d, %v",
+					tt.value, version, encrypted, tt.wantVersion, tt.wantEncrypted)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package model
+
+// CBRC20Mint is the body of a c-brc-20 mint inscription, the token is the first deploy of the ticker.
+type CBRC20Mint struct {
+	Protocol  string `json:"p"`
+	Operation string `json:"op"`
+	Tick      string `json:"tick"`
+	Amount    string `json:"amt"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the position of a keyset paginated list, it is handed to clients as an opaque string.
+type Cursor struct {
+	Id    uint64 `json:"id"`
+	Order string `json:"order"`
+}
+
+// String encodes the cursor as an url safe opaque string.
+func (c *Cursor) String() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// StringToCursor decodes an opaque cursor string.
+func StringToCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	c := &Cursor{}
+	if err := json.Unmarshal(data, c); err != nil || c.Id == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
+}
@@ -0,0 +1,372 @@
+package model
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ugorji/go/codec"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxSafeInteger is the largest integer a javascript number can represent exactly,
+	// integers out of this range are encoded as strings.
+	maxSafeInteger = 1<<53 - 1
+
+	// maxMetadataDepth is how deep arrays, maps and tags of metadata may be nested.
+	maxMetadataDepth = 1024
+
+	cborMajorUnsigned = 0
+	cborMajorNegative = 1
+	cborMajorBytes    = 2
+	cborMajorText     = 3
+	cborMajorArray    = 4
+	cborMajorMap      = 5
+	cborMajorTag      = 6
+	cborBreak         = 0xff
+
+	cborTagDateTime       = 0
+	cborTagEpochDateTime  = 1
+	cborTagPositiveBignum = 2
+	cborTagNegativeBignum = 3
+)
+
+// DecodeMetadata decodes the CBOR metadata of an inscription into json compatible values.
+// Byte strings are hex encoded, integers out of the range javascript represents exactly and bignums are
+// encoded as decimal strings, and non-string map keys are converted to their json form. The map keys that
+// convert to the same key, like 1 and "1", are refused. Bytes after the first item are ignored.
+func DecodeMetadata(data []byte) (interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	d := &metadataDecoder{data: data}
+	return d.decode(0)
+}
+
+// EncodeJsonMetadata encodes json metadata into CBOR, integers are encoded as CBOR integers.
+func EncodeJsonMetadata(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var metadata interface{}
+	if err := decoder.Decode(&metadata); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("invalid json metadata")
+	}
+
+	var buf []byte
+	if err := codec.NewEncoderBytes(&buf, &codec.CborHandle{}).Encode(cborCompatible(metadata)); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// cborCompatible converts json numbers to integers when possible and to floats otherwise.
+func cborCompatible(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = cborCompatible(value)
+		}
+		return v
+	case []interface{}:
+		for i, value := range v {
+			v[i] = cborCompatible(value)
+		}
+		return v
+	case json.Number:
+		if n, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			return n
+		}
+		if n, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return v
+}
+
+// metadataDecoder decodes CBOR items into json compatible values. The items are decoded without a schema
+// by hand, as the map keys may be maps or arrays and the negative integers may not fit an int64.
+type metadataDecoder struct {
+	data []byte
+	pos  int
+}
+
+// head reads the major type and the argument of the next item, indefinite is set for an indefinite length.
+func (d *metadataDecoder) head() (major byte, arg uint64, indefinite bool, err error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, false, io.ErrUnexpectedEOF
+	}
+	b := d.data[d.pos]
+	d.pos++
+	major, info := b>>5, b&0x1f
+	switch {
+	case info < 24:
+		return major, uint64(info), false, nil
+	case info <= 27:
+		n := 1 << (info - 24)
+		if len(d.data)-d.pos < n {
+			return 0, 0, false, io.ErrUnexpectedEOF
+		}
+		for _, c := range d.data[d.pos : d.pos+n] {
+			arg = arg<<8 | uint64(c)
+		}
+		d.pos += n
+		return major, arg, false, nil
+	case info == 31 && major >= 2 && major <= 5:
+		return major, 0, true, nil
+	}
+	return 0, 0, false, fmt.Errorf("invalid cbor item %#x at %d", b, d.pos-1)
+}
+
+// isBreak reads the break code that ends an indefinite length item if it's next.
+func (d *metadataDecoder) isBreak() bool {
+	if d.pos < len(d.data) && d.data[d.pos] == cborBreak {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+func (d *metadataDecoder) decode(depth int) (interface{}, error) {
+	if depth > maxMetadataDepth {
+		return nil, errors.New("metadata is nested too deep")
+	}
+	start := d.pos
+	major, arg, indefinite, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case cborMajorUnsigned:
+		if arg > maxSafeInteger {
+			return strconv.FormatUint(arg, 10), nil
+		}
+		return arg, nil
+	case cborMajorNegative:
+		if arg < maxSafeInteger {
+			return -1 - int64(arg), nil
+		}
+		n := new(big.Int).SetUint64(arg)
+		return n.Neg(n).Sub(n, big.NewInt(1)).String(), nil
+	case cborMajorBytes:
+		data, err := d.readString(major, arg, indefinite)
+		if err != nil {
+			return nil, err
+		}
+		return hex.EncodeToString(data), nil
+	case cborMajorText:
+		data, err := d.readString(major, arg, indefinite)
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	case cborMajorArray:
+		return d.decodeArray(arg, indefinite, depth)
+	case cborMajorMap:
+		return d.decodeMap(arg, indefinite, depth)
+	case cborMajorTag:
+		return d.decodeTag(arg, depth)
+	}
+	return simpleValue(d.data[start]&0x1f, arg)
+}
+
+// readString reads a byte or text string, the chunks of an indefinite length string are joined.
+func (d *metadataDecoder) readString(major byte, length uint64, indefinite bool) ([]byte, error) {
+	if !indefinite {
+		if length > uint64(len(d.data)-d.pos) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		data := d.data[d.pos : d.pos+int(length)]
+		d.pos += int(length)
+		return data, nil
+	}
+	data := make([]byte, 0)
+	for !d.isBreak() {
+		chunkMajor, chunkLength, chunkIndefinite, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || chunkIndefinite {
+			return nil, fmt.Errorf("invalid chunk of an indefinite length string at %d", d.pos)
+		}
+		chunk, err := d.readString(major, chunkLength, false)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, chunk...)
+	}
+	return data, nil
+}
+
+func (d *metadataDecoder) decodeArray(length uint64, indefinite bool, depth int) ([]interface{}, error) {
+	// every item takes at least a byte
+	if length > uint64(len(d.data)-d.pos) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	list := make([]interface{}, 0, length)
+	for i := uint64(0); indefinite || i < length; i++ {
+		if indefinite && d.isBreak() {
+			break
+		}
+		value, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+	}
+	return list, nil
+}
+
+func (d *metadataDecoder) decodeMap(length uint64, indefinite bool, depth int) (map[string]interface{}, error) {
+	// every key and value takes at least a byte
+	if length > uint64(len(d.data)-d.pos)/2 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	m := make(map[string]interface{}, length)
+	for i := uint64(0); indefinite || i < length; i++ {
+		if indefinite && d.isBreak() {
+			break
+		}
+		key, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		k := metadataKey(key)
+		if _, ok := m[k]; ok {
+			return nil, fmt.Errorf("duplicate map key %s", k)
+		}
+		m[k] = value
+	}
+	return m, nil
+}
+
+// decodeTag decodes a tagged item. Bignums are decoded, date times are converted to RFC 3339 in UTC,
+// and other tags are kept as a tag and value pair.
+func (d *metadataDecoder) decodeTag(tag uint64, depth int) (interface{}, error) {
+	start := d.pos
+	if tag == cborTagPositiveBignum || tag == cborTagNegativeBignum {
+		major, length, indefinite, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if major == cborMajorBytes {
+			data, err := d.readString(major, length, indefinite)
+			if err != nil {
+				return nil, err
+			}
+			n := new(big.Int).SetBytes(data)
+			if tag == cborTagNegativeBignum {
+				n.Neg(n).Sub(n, big.NewInt(1))
+			}
+			return n.String(), nil
+		}
+		d.pos = start
+	}
+
+	value, err := d.decode(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+	switch v := value.(type) {
+	case string:
+		if tag == cborTagDateTime {
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return t.UTC().Format(time.RFC3339Nano), nil
+			}
+		}
+	case uint64:
+		if tag == cborTagEpochDateTime {
+			return time.Unix(int64(v), 0).UTC().Format(time.RFC3339Nano), nil
+		}
+	case int64:
+		if tag == cborTagEpochDateTime {
+			return time.Unix(v, 0).UTC().Format(time.RFC3339Nano), nil
+		}
+	case float64:
+		if tag == cborTagEpochDateTime {
+			sec, frac := math.Modf(v)
+			return time.Unix(int64(sec), int64(frac*1e9)).UTC().Format(time.RFC3339Nano), nil
+		}
+	}
+	return map[string]interface{}{
+		"tag":   tag,
+		"value": value,
+	}, nil
+}
+
+// simpleValue converts a simple value or a float of major type 7 with its additional info and argument.
+func simpleValue(info byte, arg uint64) (interface{}, error) {
+	var f float64
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		f = float16ToFloat(uint16(arg))
+	case 26:
+		f = float64(math.Float32frombits(uint32(arg)))
+	case 27:
+		f = math.Float64frombits(arg)
+	default:
+		return map[string]interface{}{"simple": arg}, nil
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, 64), nil
+	}
+	return f, nil
+}
+
+// float16ToFloat converts an IEEE 754 half precision float.
+func float16ToFloat(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
+
+// metadataKey converts a decoded CBOR map key to a json object key.
+func metadataKey(key interface{}) string {
+	switch key := key.(type) {
+	case string:
+		return key
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(key)
+		return string(data)
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprint(key)
+	}
+}
@@ -0,0 +1,120 @@
+package model
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDecodeMetadata(t *testing.T) {
+	tests := []struct {
+		name string
+		hex  string
+		want string
+	}{
+		{"empty map", "a0", `{}`},
+		{"small int", "01", `1`},
+		{"max safe int", "1b001fffffffffffff", `9007199254740991`},
+		{"unsafe int", "1b0020000000000000", `"9007199254740992"`},
+		{"negative int", "20", `-1`},
+		{"min safe int", "3b001ffffffffffffe", `-9007199254740991`},
+		{"unsafe negative int", "3b001fffffffffffff", `"-9007199254740992"`},
+		{"min negative int", "3bffffffffffffffff", `"-18446744073709551616"`},
+		{"positive bignum", "c249010000000000000000", `"18446744073709551616"`},
+		{"negative bignum", "c349010000000000000000", `"-18446744073709551617"`},
+		{"bytes", "4401020304", `"01020304"`},
+		{"indefinite bytes", "5f42010243030405ff", `"0102030405"`},
+		{"text", "6161", `"a"`},
+		{"indefinite text", "7f61616162ff", `"ab"`},
+		{"indefinite array", "9f0102ff", `[1,2]`},
+		{"int keys", "a201020304", `{"1":2,"3":4}`},
+		{"map key", "a1a161610102", `{"{\"a\":1}":2}`},
+		{"array key", "a182010203", `{"[1,2]":3}`},
+		{"indefinite map", "bf616101ff", `{"a":1}`},
+		{"false", "f4", `false`},
+		{"true", "f5", `true`},
+		{"null", "f6", `null`},
+		{"undefined", "f7", `null`},
+		{"simple value", "f820", `{"simple":32}`},
+		{"half float", "f93e00", `1.5`},
+		{"half float infinity", "f97c00", `"+Inf"`},
+		{"float", "fa3fc00000", `1.5`},
+		{"double", "fb3ff8000000000000", `1.5`},
+		{"date time", "c074323031332d30332d32315432303a30343a30305a", `"2013-03-21T20:04:00Z"`},
+		{"epoch date time", "c11a514b67b0", `"2013-03-21T20:04:00Z"`},
+		{"other tag", "d82076687474703a2f2f7777772e6578616d706c652e636f6d", `{"tag":32,"value":"http://www.example.com"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tt.hex)
+			if err != nil {
+				t.Fatal(err)
+			}
+			metadata, err := DecodeMetadata(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.Marshal(metadata)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("DecodeMetadata(%s) = %s, want %s", tt.hex, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeMetadataInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		hex  string
+	}{
+		{"truncated text", "6261"},
+		{"truncated argument", "1a0102"},
+		{"invalid additional info", "1c"},
+		{"huge array", "9bffffffffffffffff"},
+		{"huge map", "bbffffffffffffffff"},
+		{"unexpected break", "ff"},
+		{"colliding keys", "a20101613102"},
+		{"duplicate keys", "a2616101616102"},
+		{"invalid chunk", "5f6161ff"},
+		{"too deep", strings.Repeat("81", maxMetadataDepth+1) + "00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tt.hex)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if metadata, err := DecodeMetadata(data); err == nil {
+				t.Fatalf("DecodeMetadata(%s) = %v, want an error", tt.hex, metadata)
+			}
+		})
+	}
+}
+
+func TestEncodeJsonMetadata(t *testing.T) {
+	const metadata = `{"a":[1,-2,"x",1.5],"b":{"c":null},"d":18446744073709551615}`
+	data, err := EncodeJsonMetadata([]byte(metadata))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeMetadata(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `{"a":[1,-2,"x",1.5],"b":{"c":null},"d":"18446744073709551615"}`
+	if string(got) != want {
+		t.Fatalf("round trip of %s = %s, want %s", metadata, got, want)
+	}
+
+	if _, err := EncodeJsonMetadata([]byte(`{"a":1} {}`)); err == nil {
+		t.Fatal("encoded two json values")
+	}
+}
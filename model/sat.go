@@ -0,0 +1,205 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"github.com/inscription-c/cins/inscription/index"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidSat = errors.New("invalid sat")
+
+// halvingIncrement is how much the difficulty adjustment period offset shifts every halving,
+// it is used to tell the epoch of a degree.
+const halvingIncrement = index.SubsidyHalvingInterval % index.DiffChangeInterval
+
+var rarityNames = []string{
+	index.RarityCommon:    "common",
+	index.RarityUncommon:  "uncommon",
+	index.RarityRare:      "rare",
+	index.RarityEpic:      "epic",
+	index.RarityLegendary: "legendary",
+	index.RarityMythic:    "mythic",
+}
+
+// RarityName return the lower case name of a rarity.
+func RarityName(rarity index.Rarity) string {
+	if int(rarity) >= len(rarityNames) {
+		return ""
+	}
+	return rarityNames[rarity]
+}
+
+// StringToRarity parses a rarity name, it returns false if the name is unknown.
+func StringToRarity(s string) (index.Rarity, bool) {
+	for i, name := range rarityNames {
+		if name == s {
+			return index.Rarity(i), true
+		}
+	}
+	return 0, false
+}
+
+// StringToSat parses a sat in integer, decimal (height.offset), degree (A°B′C″D‴), percentile (P%) or name notation.
+func StringToSat(s string) (index.Sat, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return 0, ErrInvalidSat
+	case strings.Contains(s, "°"):
+		return degreeToSat(s)
+	case strings.HasSuffix(s, "%"):
+		return percentileToSat(s)
+	case strings.Contains(s, "."):
+		return decimalToSat(s)
+	case strings.Trim(s, "0123456789") == "":
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil || n >= index.SupplySat {
+			return 0, ErrInvalidSat
+		}
+		return index.Sat(n), nil
+	default:
+		return nameToSat(s)
+	}
+}
+
+func decimalToSat(s string) (index.Sat, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 2 {
+		return 0, ErrInvalidSat
+	}
+	height, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return 0, ErrInvalidSat
+	}
+	offset, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return 0, ErrInvalidSat
+	}
+	return heightOffsetToSat(uint32(height), offset)
+}
+
+// degreeToSat follows the ord degree notation, the third part is optional.
+func degreeToSat(s string) (index.Sat, error) {
+	var hour, minute, second, third uint64
+	rest := s
+	for _, part := range []struct {
+		unit  string
+		value *uint64
+	}{
+		{"°", &hour},
+		{"′", &minute},
+		{"″", &second},
+		{"‴", &third},
+	} {
+		if rest == "" && part.unit == "‴" {
+			break
+		}
+		idx := strings.Index(rest, part.unit)
+		if idx <= 0 {
+			return 0, ErrInvalidSat
+		}
+		n, err := strconv.ParseUint(rest[:idx], 10, 64)
+		if err != nil {
+			return 0, ErrInvalidSat
+		}
+		*part.value = n
+		rest = rest[idx+len(part.unit):]
+	}
+	if rest != "" {
+		return 0, ErrInvalidSat
+	}
+	if minute >= uint64(index.SubsidyHalvingInterval) || second >= uint64(index.DiffChangeInterval) {
+		return 0, ErrInvalidSat
+	}
+
+	relationship := second + uint64(index.SubsidyHalvingInterval*index.CycleEpochs) - minute
+	if relationship%uint64(halvingIncrement) != 0 {
+		return 0, ErrInvalidSat
+	}
+	epochsSinceCycleStart := relationship % uint64(index.DiffChangeInterval) / uint64(halvingIncrement)
+	epoch := hour*uint64(index.CycleEpochs) + epochsSinceCycleStart
+	height := epoch*uint64(index.SubsidyHalvingInterval) + minute
+	if height > uint64(^uint32(0)) {
+		return 0, ErrInvalidSat
+	}
+	return heightOffsetToSat(uint32(height), third)
+}
+
+// percentileToSat follows the ord percentile notation, the sat at a percentile of the last sat rounded to the nearest.
+func percentileToSat(s string) (index.Sat, error) {
+	percentile, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil || percentile < 0 || math.IsNaN(percentile) {
+		return 0, ErrInvalidSat
+	}
+	last := float64(index.SupplySat - 1)
+	n := math.Round(percentile / 100 * last)
+	if n > last {
+		return 0, ErrInvalidSat
+	}
+	return index.Sat(n), nil
+}
+
+func nameToSat(s string) (index.Sat, error) {
+	var x uint64
+	for _, c := range s {
+		if c < 'a' || c > 'z' {
+			return 0, ErrInvalidSat
+		}
+		x = x*26 + uint64(c-'a') + 1
+		if x > index.SupplySat {
+			return 0, ErrInvalidSat
+		}
+	}
+	if x == 0 {
+		return 0, ErrInvalidSat
+	}
+	return index.Sat(index.SupplySat - x), nil
+}
+
+func heightOffsetToSat(height uint32, offset uint64) (index.Sat, error) {
+	h := index.NewHeight(height)
+	if offset >= h.Subsidy() {
+		return 0, ErrInvalidSat
+	}
+	return h.StartingSat() + index.Sat(offset), nil
+}
+
+// SatName return the name notation of a sat, the first mined sats have the longest names.
+func SatName(sat index.Sat) string {
+	x := index.SupplySat - sat.N()
+	name := make([]byte, 0, 11)
+	for x > 0 {
+		name = append(name, byte('a'+(x-1)%26))
+		x = (x - 1) / 26
+	}
+	for i, j := 0, len(name)-1; i < j; i, j = i+1, j-1 {
+		name[i], name[j] = name[j], name[i]
+	}
+	return string(name)
+}
+
+// SatDecimal return the decimal notation of a sat, which is the block height and the offset in the block.
+func SatDecimal(sat index.Sat) string {
+	return fmt.Sprintf("%d.%d", sat.Height().N(), sat.Third())
+}
+
+// SatDegree return the degree notation of a sat.
+func SatDegree(sat index.Sat) string {
+	height := sat.Height().N()
+	return fmt.Sprintf("%d°%d′%d″%d‴",
+		SatCycle(sat), height%index.SubsidyHalvingInterval, height%index.DiffChangeInterval, sat.Third())
+}
+
+// SatEpoch return the halving epoch of a sat.
+func SatEpoch(sat index.Sat) uint32 {
+	return sat.Height().N() / index.SubsidyHalvingInterval
+}
+
+// SatCycle return the cycle of a sat, a cycle is the period in which a halving and
+// a difficulty adjustment coincide.
+func SatCycle(sat index.Sat) uint32 {
+	return sat.Height().N() / (index.CycleEpochs * index.SubsidyHalvingInterval)
+}
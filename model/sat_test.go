@@ -0,0 +1,135 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/inscription-c/cins/inscription/index"
+)
+
+// coin is the number of sats of a bitcoin.
+const coin = 100000000
+
+// TestStringToSat follows the parsing tests of ord.
+func TestStringToSat(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    index.Sat
+		wantErr bool
+	}{
+		// integer
+		{s: "0", want: 0},
+		{s: "1", want: 1},
+		{s: "2099999997689999", want: index.LastSupplySat},
+		{s: "2099999997690000", wantErr: true},
+		{s: "-1", wantErr: true},
+
+		// decimal
+		{s: "0.0", want: 0},
+		{s: "0.1", want: 1},
+		{s: "1.0", want: 50 * coin},
+		{s: "6929999.0", want: index.LastSupplySat},
+		{s: "0.5000000000", wantErr: true},
+		{s: "6930000.0", wantErr: true},
+		{s: "1.2.3", wantErr: true},
+
+		// degree
+		{s: "0°0′0″0‴", want: 0},
+		{s: "0°0′0″", want: 0},
+		{s: "0°0′0″1‴", want: 1},
+		{s: "0°2016′0″0‴", want: 2016 * 50 * coin},
+		{s: "0°2016′0″1‴", want: 2016*50*coin + 1},
+		{s: "0°40320′0″0‴", want: 40320 * 50 * coin},
+		{s: "0°0′336″0‴", want: 210000 * 50 * coin},
+		{s: "0°0′672″0‴", want: 210000*50*coin + 210000*25*coin},
+		{s: "1°0′0″0‴", want: 2067187500000000},
+		{s: "1°0′0″1‴", want: 2067187500000001},
+		{s: "0°1′0″0‴", wantErr: true},
+		{s: "0°0′2016″0‴", wantErr: true},
+		{s: "0°210000′0″0‴", wantErr: true},
+		{s: "0°0′0″5000000000‴", wantErr: true},
+		{s: "0°0′", wantErr: true},
+
+		// percentile
+		{s: "0%", want: 0},
+		{s: "1%", want: 20999999976900},
+		{s: "1.5%", want: 31499999965350},
+		{s: "100%", want: index.LastSupplySat},
+		{s: "101%", wantErr: true},
+		{s: "-10.0%", wantErr: true},
+		{s: "abc%", wantErr: true},
+
+		// name
+		{s: "nvtdijuwxlp", want: 0},
+		{s: "nvtdijuwxlo", want: 1},
+		{s: "a", want: index.LastSupplySat},
+		{s: "z", want: index.SupplySat - 26},
+		{s: "aa", want: index.SupplySat - 27},
+		{s: "nvtdijuwxlq", wantErr: true},
+		{s: "A", wantErr: true},
+
+		{s: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := StringToSat(tt.s)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("StringToSat(%q) = %d, want an error", tt.s, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("StringToSat(%q) err %v", tt.s, err)
+			}
+			if got != tt.want {
+				t.Fatalf("StringToSat(%q) = %d, want %d", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSatNotations(t *testing.T) {
+	tests := []struct {
+		sat     index.Sat
+		name    string
+		decimal string
+		degree  string
+	}{
+		{0, "nvtdijuwxlp", "0.0", "0°0′0″0‴"},
+		{1, "nvtdijuwxlo", "0.1", "0°0′0″1‴"},
+		{50 * coin, "nvtcsezkbth", "1.0", "0°1′1″0‴"},
+		{210000 * 50 * coin, "gkjbdrhkfqf", "210000.0", "0°0′336″0‴"},
+		{index.LastSupplySat, "a", "6929999.0", "5°209999′1007″0‴"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.decimal, func(t *testing.T) {
+			if got := SatName(tt.sat); got != tt.name {
+				t.Errorf("SatName(%d) = %s, want %s", tt.sat, got, tt.name)
+			}
+			if got := SatDecimal(tt.sat); got != tt.decimal {
+				t.Errorf("SatDecimal(%d) = %s, want %s", tt.sat, got, tt.decimal)
+			}
+			if got := SatDegree(tt.sat); got != tt.degree {
+				t.Errorf("SatDegree(%d) = %s, want %s", tt.sat, got, tt.degree)
+			}
+			for _, s := range []string{tt.name, tt.decimal, tt.degree} {
+				if got, err := StringToSat(s); err != nil || got != tt.sat {
+					t.Errorf("StringToSat(%q) = %d, %v, want %d", s, got, err, tt.sat)
+				}
+			}
+		})
+	}
+}
+
+func TestStringToRarity(t *testing.T) {
+	for _, rarity := range []index.Rarity{index.RarityCommon, index.RarityUncommon, index.RarityRare,
+		index.RarityEpic, index.RarityLegendary, index.RarityMythic} {
+		got, ok := StringToRarity(RarityName(rarity))
+		if !ok || got != rarity {
+			t.Errorf("StringToRarity(%q) = %d, %v, want %d", RarityName(rarity), got, ok, rarity)
+		}
+	}
+	if _, ok := StringToRarity("shiny"); ok {
+		t.Error("StringToRarity parsed an unknown rarity")
+	}
+}
@@ -0,0 +1,307 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/inscription-c/cins/constants"
+	"github.com/inscription-c/cins/inscription"
+	"github.com/inscription-c/cins/pkg/signal"
+	"github.com/inscription-c/cins/pkg/util/txscript"
+	"github.com/inscription-c/explorer-api/log"
+	"github.com/inscription-c/explorer-api/tables"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultBumpAfter is how long a reveal tx stays unconfirmed before it's replaced by default.
+	defaultBumpAfter = time.Minute * 30
+	// defaultMinPostage is the value an inscription output can be reduced to by default.
+	defaultMinPostage = 330
+	// bumpConfTarget is the confirmation target of the fee estimate of automatic replacements.
+	bumpConfTarget = 2
+)
+
+var errBumpValueTooLow = errors.New("reveal tx value is not enough for the replacement fee")
+
+// BumpRevealTxs replaces the reveal txs that stay unconfirmed for too long, or that have a requested bump,
+// by a tx with a higher fee rate. The reveal txs signal replace-by-fee, CPFP is not possible because
+// the outputs of a reveal tx belong to the user.
+func (b *Runner) BumpRevealTxs() {
+	b.Go(func() error {
+		ticker := time.NewTicker(time.Second * 30)
+		defer ticker.Stop()
+		for range ticker.C {
+			select {
+			case <-signal.InterruptChannel:
+				return nil
+			default:
+				if err := b.bumpRevealTxs(); err != nil {
+					log.Log.Errorf("bumpRevealTxs err: %s", err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (b *Runner) bumpRevealTxs() error {
+	orders, err := b.db.FindBumpableInscribeOrders(time.Now().Add(-b.bumpAfter))
+	if err != nil {
+		return err
+	}
+	if len(orders) == 0 {
+		return nil
+	}
+
+	estimateFeeRate := int64(0)
+	for _, order := range orders {
+		feeRate := order.BumpFeeRate
+		if b.maxFeeRate > 0 && feeRate > b.maxFeeRate {
+			feeRate = b.maxFeeRate
+		}
+		if feeRate <= order.FeeRate {
+			if estimateFeeRate == 0 {
+				if estimateFeeRate, err = b.bumpTxFeeRate(); err != nil {
+					return err
+				}
+			}
+			feeRate = estimateFeeRate
+		}
+		if feeRate <= order.FeeRate {
+			continue
+		}
+		if err := b.bumpOrder(order, feeRate); err != nil {
+			log.Log.Error("BumpOrderError", err, order.OrderId)
+		}
+	}
+	return nil
+}
+
+// bumpOrder replaces the reveal tx of an order by a tx at the fee rate, unless the reveal tx is confirmed.
+func (b *Runner) bumpOrder(order *tables.InscribeOrder, feeRate int64) error {
+	confirmed, err := b.revealTxConfirmed(order)
+	if err != nil {
+		return err
+	}
+	if confirmed {
+		return nil
+	}
+
+	revealTx, err := b.bumpRevealTx(order, feeRate)
+	if errors.Is(err, errBumpValueTooLow) {
+		log.Log.Warn("BumpValueTooLow", order.OrderId, feeRate)
+		return b.db.Model(order).Update("bump_fee_rate", 0).Error
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := b.client.SendRawTransaction(revealTx, false); err != nil {
+		log.Log.Error("BumpTxSendError", err, order.OrderId)
+		return nil
+	}
+
+	revealTxBuf := bytes.NewBufferString("")
+	if err := revealTx.Serialize(revealTxBuf); err != nil {
+		return err
+	}
+	replacedTxIds := order.RevealTxId
+	if order.ReplacedTxIds != "" {
+		replacedTxIds = order.ReplacedTxIds + "," + replacedTxIds
+	}
+	order.ReplacedTxIds = replacedTxIds
+	order.RevealTxId = revealTx.TxHash().String()
+	order.RevealTxRaw = hex.EncodeToString(revealTxBuf.Bytes())
+	order.RevealSentAt = time.Now()
+	order.FeeRate = feeRate
+	order.BumpFeeRate = 0
+	order.BumpCount++
+	if err := b.db.SaveInscribeOrder(order); err != nil {
+		return err
+	}
+	if err := b.db.CreateInscribeOrderEvent(&tables.InscribeOrderEvent{
+		OrderId: order.OrderId,
+		Type:    tables.OrderEventRevealReplaced,
+		Status:  order.Status,
+		TxId:    order.RevealTxId,
+		Message: fmt.Sprintf("replaced %s at fee rate %d", replacedTxIds[strings.LastIndex(replacedTxIds, ",")+1:], feeRate),
+	}); err != nil {
+		return err
+	}
+	log.Log.Infof("BumpTxSendSuccess %s %s %d", order.OrderId, order.RevealTxId, feeRate)
+	return nil
+}
+
+// revealTxConfirmed return whether the reveal tx of an order is confirmed, processRevealTx updates the order later.
+func (b *Runner) revealTxConfirmed(order *tables.InscribeOrder) (bool, error) {
+	revealTxHash, err := chainhash.NewHashFromStr(order.RevealTxId)
+	if err != nil {
+		return false, err
+	}
+	tx, err := b.client.GetRawTransactionVerbose(revealTxHash)
+	var jsonErr *btcjson.RPCError
+	if errors.As(err, &jsonErr) && jsonErr.Code == btcjson.ErrRPCNoTxInfo {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return tx.Confirmations > 0, nil
+}
+
+// bumpTxFeeRate return the fee estimate of the node for automatic replacements, limited by the max fee rate.
+func (b *Runner) bumpTxFeeRate() (int64, error) {
+	resp, err := b.client.EstimateSmartFee(bumpConfTarget, &btcjson.EstimateModeConservative)
+	if err != nil {
+		return 0, err
+	}
+	if resp.FeeRate == nil {
+		return 0, errors.New("fee estimate is not available")
+	}
+	feeRate := int64(math.Ceil(*resp.FeeRate * float64(constants.OneBtc)))
+	if b.maxFeeRate > 0 && feeRate > b.maxFeeRate {
+		feeRate = b.maxFeeRate
+	}
+	return feeRate, nil
+}
+
+// bumpRevealTx builds the replacement of the reveal tx of an order with a higher fee rate.
+// The replacement pays at least the fee of the replaced tx plus its own relay fee. The fee increase is paid
+// by the change output first, then by the last inscription output down to the min postage.
+func (b *Runner) bumpRevealTx(order *tables.InscribeOrder, feeRate int64) (*wire.MsgTx, error) {
+	revealTxData, err := hex.DecodeString(order.RevealTxRaw)
+	if err != nil {
+		return nil, err
+	}
+	revealTx := &wire.MsgTx{}
+	if err := revealTx.Deserialize(bytes.NewReader(revealTxData)); err != nil {
+		return nil, err
+	}
+
+	payments, err := b.revealTxPayments(order, revealTx)
+	if err != nil {
+		return nil, err
+	}
+	if len(payments) != len(revealTx.TxIn) {
+		return nil, errors.New("payments do not match the inputs of the reveal tx")
+	}
+	inputValue := int64(0)
+	for _, payment := range payments {
+		inputValue += payment.Value
+	}
+	replacedFee := inputValue - outputsValue(revealTx)
+
+	// the change output is the last output, it's dropped to the fee when it's below the dust limit
+	if order.ChangeValue > 0 {
+		changeOut := revealTx.TxOut[len(revealTx.TxOut)-1]
+		changeOut.Value -= replacementFee(revealTx, feeRate, replacedFee) - replacedFee
+		order.ChangeValue = changeOut.Value
+		if changeOut.Value < dustLimit(changeOut.PkScript) {
+			revealTx.TxOut = revealTx.TxOut[:len(revealTx.TxOut)-1]
+			order.ChangeValue = 0
+		}
+	}
+
+	if missing := replacementFee(revealTx, feeRate, replacedFee) - (inputValue - outputsValue(revealTx)); missing > 0 {
+		// the pointers of the reveal script must still point into the last inscription output
+		postageOut := revealTx.TxOut[len(revealTx.TxOut)-1]
+		postageStart := outputsValue(revealTx) - postageOut.Value
+		minPostage := b.minPostage
+		if limit := dustLimit(postageOut.PkScript); minPostage < limit {
+			minPostage = limit
+		}
+		if end := revealPointerEnd(revealTx.TxIn[0].Witness[1]) - postageStart; minPostage < end {
+			minPostage = end
+		}
+		if postageOut.Value-missing < minPostage {
+			return nil, errBumpValueTooLow
+		}
+		postageOut.Value -= missing
+	}
+
+	if err := b.signRevealInputs(revealTx, order, payments); err != nil {
+		return nil, err
+	}
+	return revealTx, nil
+}
+
+// revealTxPayments return the payments spent by the reveal tx of an order. Orders created before the payments
+// were recorded spend only the commit output, its payment is made from the input of the reveal tx.
+func (b *Runner) revealTxPayments(order *tables.InscribeOrder, revealTx *wire.MsgTx) ([]*tables.InscribeOrderPayment, error) {
+	payments, err := b.db.FindInscribeOrderPayments(order.OrderId)
+	if err != nil || len(payments) > 0 || len(revealTx.TxIn) != 1 {
+		return payments, err
+	}
+
+	prevOut := revealTx.TxIn[0].PreviousOutPoint
+	payment := &tables.InscribeOrderPayment{
+		OrderId: order.OrderId,
+		TxId:    prevOut.Hash.String(),
+		Index:   prevOut.Index,
+	}
+	if order.CommitTxValue > 0 && order.CommitTxId == payment.TxId && order.CommitTxIndex == payment.Index {
+		payment.Value = order.CommitTxValue
+		return []*tables.InscribeOrderPayment{payment}, nil
+	}
+	commitTx, err := b.client.GetRawTransaction(&prevOut.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if int(prevOut.Index) >= len(commitTx.MsgTx().TxOut) {
+		return nil, errors.New("reveal tx input is not an output of the commit tx")
+	}
+	payment.Value = commitTx.MsgTx().TxOut[prevOut.Index].Value
+	return []*tables.InscribeOrderPayment{payment}, nil
+}
+
+// replacementFee return the fee of a replacement tx, it's at least the fee of the replaced tx
+// plus the relay fee of the replacement at the min relay fee rate.
+func replacementFee(tx *wire.MsgTx, feeRate, replacedFee int64) int64 {
+	fee := inscription.CalculateTxFee(tx, feeRate)
+	vsize := int64(tx.SerializeSizeStripped()*3+tx.SerializeSize()+3) / 4
+	if fee < replacedFee+vsize {
+		fee = replacedFee + vsize
+	}
+	return fee
+}
+
+func outputsValue(tx *wire.MsgTx) int64 {
+	value := int64(0)
+	for _, txOut := range tx.TxOut {
+		value += txOut.Value
+	}
+	return value
+}
+
+// revealPointerEnd return the offset after the highest pointer of the envelopes of a reveal script.
+// The fields of an envelope are tag and value pairs up to the body tag.
+func revealPointerEnd(revealScript []byte) int64 {
+	end := int64(0)
+	tokenizer := txscript.MakeScriptTokenizer(0, revealScript)
+	for tokenizer.Next() {
+		if !bytes.Equal(tokenizer.Data(), []byte(constants.ProtocolId)) {
+			continue
+		}
+		for tokenizer.Next() && tokenizer.Opcode() != txscript.OP_ENDIF && tokenizer.Opcode() != txscript.OP_0 {
+			tag := tokenizer.Opcode()
+			if !tokenizer.Next() {
+				break
+			}
+			if tag != txscript.OP_2 {
+				continue
+			}
+			pointer, err := strconv.ParseInt(strings.TrimSpace(string(tokenizer.Data())), 10, 64)
+			if err == nil && pointer >= end {
+				end = pointer + 1
+			}
+		}
+	}
+	return end
+}
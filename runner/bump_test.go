@@ -0,0 +1,34 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/inscription-c/explorer-api/tables"
+)
+
+func TestReplacementFee(t *testing.T) {
+	order := &tables.InscribeOrder{ReceiveAddress: testTaprootAddress(t, 1), FeeRate: 1000}
+	tx := testRevealTx(t, order)
+	weight := int64(tx.SerializeSizeStripped()*3 + tx.SerializeSize())
+	vsize := (weight + 3) / 4
+
+	tests := []struct {
+		name        string
+		feeRate     int64
+		replacedFee int64
+		want        int64
+	}{
+		{name: "fee rate pays the relay fee", feeRate: 20000, replacedFee: 1000, want: weight * 20000 / 4000},
+		{name: "fee rate in sat/kvB", feeRate: 12500, replacedFee: 600, want: weight * 12500 / 4000},
+		{name: "small increase pays the relay fee", feeRate: 11000, replacedFee: weight * 10000 / 4000,
+			want: weight*10000/4000 + vsize},
+		{name: "min fee of cins", feeRate: 1000, replacedFee: 0, want: 546},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := replacementFee(tx, tt.feeRate, tt.replacedFee); got != tt.want {
+				t.Errorf("replacementFee(%d, %d) = %d, want %d", tt.feeRate, tt.replacedFee, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/inscription-c/cins/pkg/util"
+	"github.com/inscription-c/explorer-api/constants"
+	"github.com/inscription-c/explorer-api/dao"
+	"github.com/inscription-c/explorer-api/log"
+	"github.com/inscription-c/explorer-api/model"
+	"github.com/inscription-c/explorer-api/tables"
+	"strconv"
+	"strings"
+)
+
+// cbrc20KeepBlocks is the number of parsed blocks kept to find the fork point of a reorg.
+const cbrc20KeepBlocks uint32 = 50
+
+// tagPointer is the envelope tag of the pointer.
+const tagPointer = 2
+
+type cbrc20Deploy struct {
+	protocol    tables.Protocol
+	inscription tables.Inscriptions
+}
+
+// CBRC20Parser follows the blocks of the indexer and records the valid c-brc-20 tokens and mints.
+func (b *Runner) CBRC20Parser() {
+	b.followBlocks(b.cbrc20Follower)
+}
+
+// cbrc20Follower return the follower of the c-brc-20 tokens and mints.
+func (b *Runner) cbrc20Follower() *blockFollower {
+	return &blockFollower{
+		name: "CBRC20",
+		last: func() (*parsedBlock, error) {
+			info, err := b.db.LastCBRC20ParserInfo()
+			return &parsedBlock{Height: info.Height, BlockHash: info.BlockHash}, err
+		},
+		parsed: func() ([]*parsedBlock, error) {
+			infos, err := b.db.FindCBRC20ParserInfos()
+			list := make([]*parsedBlock, 0, len(infos))
+			for _, info := range infos {
+				list = append(list, &parsedBlock{Height: info.Height, BlockHash: info.BlockHash})
+			}
+			return list, err
+		},
+		parse: func(block *parsedBlock) error {
+			tokens, err := b.parseCBRC20Deploys(block.Height)
+			if err != nil {
+				return err
+			}
+			mints, err := b.parseCBRC20Mints(block.Height)
+			if err != nil {
+				return err
+			}
+			info := &tables.CBRC20ParserInfo{
+				Height:    block.Height,
+				BlockHash: block.BlockHash,
+			}
+			if err := b.db.Transaction(func(wtx *dao.DB) error {
+				return wtx.CreateCBRC20Block(info, tokens, mints, cbrc20KeepBlocks)
+			}); err != nil {
+				return err
+			}
+			if len(tokens) > 0 || len(mints) > 0 {
+				log.Log.Infof("CBRC20 height: %d deploys: %d mints: %d", block.Height, len(tokens), len(mints))
+			}
+			return nil
+		},
+		deleteFrom: func(height uint32) error {
+			return b.db.Transaction(func(wtx *dao.DB) error {
+				return wtx.DeleteCBRC20From(height)
+			})
+		},
+	}
+}
+
+// parseCBRC20Deploys return the tokens deployed in a block, a deploy is a token only if it's the first deploy of the ticker.
+func (b *Runner) parseCBRC20Deploys(height uint32) ([]*tables.CBRC20Token, error) {
+	list, err := b.indexerDB.FindDeployProtocolsInBlock(height)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]*tables.CBRC20Token, 0)
+	for _, deploy := range list {
+		first, err := b.indexerDB.GetDeployProtocolByTicker(deploy.Ticker)
+		if err != nil {
+			return nil, err
+		}
+		if first.SequenceNum != deploy.SequenceNum {
+			continue
+		}
+		ins, err := b.indexerDB.GetInscriptionBySequenceNum(deploy.SequenceNum)
+		if err != nil {
+			return nil, err
+		}
+		if ins.Id == 0 {
+			continue
+		}
+		tokens = append(tokens, &tables.CBRC20Token{
+			InscriptionId:   deploy.InscriptionId,
+			TkID:            deploy.InscriptionId.String(),
+			SequenceNum:     deploy.SequenceNum,
+			Height:          height,
+			Ticker:          deploy.Ticker,
+			Max:             deploy.Max,
+			Limit:           deploy.Limit,
+			Decimals:        deploy.Decimals,
+			CInsDescription: ins.CInsDescription,
+			Owner:           deploy.Owner,
+			Timestamp:       ins.Timestamp,
+		})
+	}
+	return tokens, nil
+}
+
+// parseCBRC20Mints return the valid mints of a block. A mint is valid if the ticker is deployed before it,
+// it has the same CIns description as the deploy, and the amount is not above the limit per mint.
+// The last mint is cut to the remaining supply. The amounts are whole tokens as cins keeps the max and the limit
+// of a deploy, a mint with a fractional amount is not valid. A mint is credited to the address of the reveal tx output
+// receiving the inscription, a mint sent to the fee is not valid.
+func (b *Runner) parseCBRC20Mints(height uint32) ([]*tables.CBRC20Mint, error) {
+	list, err := b.indexerDB.FindCBRC20InscriptionsInBlock(height)
+	if err != nil {
+		return nil, err
+	}
+
+	mints := make([]*tables.CBRC20Mint, 0)
+	txs := make(map[string]*btcutil.Tx)
+	deploys := make(map[string]*cbrc20Deploy)
+	minted := make(map[string]uint64)
+	for _, ins := range list {
+		if ins.ContentEncoding != "" {
+			continue
+		}
+		mint := &model.CBRC20Mint{}
+		if err := json.Unmarshal(ins.Body, mint); err != nil {
+			continue
+		}
+		if mint.Protocol != constants.ProtocolCBRC20 || mint.Operation != constants.OperationMint {
+			continue
+		}
+		amount, err := strconv.ParseUint(mint.Amount, 10, 64)
+		if err != nil || amount == 0 {
+			continue
+		}
+
+		deploy, ok := deploys[mint.Tick]
+		if !ok {
+			deploy = &cbrc20Deploy{}
+			deploy.protocol, err = b.indexerDB.GetDeployProtocolByTicker(mint.Tick)
+			if err != nil {
+				return nil, err
+			}
+			if deploy.protocol.Id > 0 {
+				deploy.inscription, err = b.indexerDB.GetInscriptionBySequenceNum(deploy.protocol.SequenceNum)
+				if err != nil {
+					return nil, err
+				}
+			}
+			deploys[mint.Tick] = deploy
+		}
+		if deploy.inscription.Id == 0 || deploy.inscription.SequenceNum >= ins.SequenceNum {
+			continue
+		}
+		if ins.CInsDescription.Chain != deploy.inscription.CInsDescription.Chain ||
+			ins.CInsDescription.Contract != deploy.inscription.CInsDescription.Contract {
+			continue
+		}
+		if deploy.protocol.Limit > 0 && amount > deploy.protocol.Limit {
+			continue
+		}
+
+		tkid := deploy.protocol.InscriptionId.String()
+		total, ok := minted[tkid]
+		if !ok {
+			total, err = b.db.SumCBRC20Minted(tkid)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if deploy.protocol.Max > 0 {
+			if total >= deploy.protocol.Max {
+				continue
+			}
+			if amount > deploy.protocol.Max-total {
+				amount = deploy.protocol.Max - total
+			}
+		}
+		tx, ok := txs[ins.TxId]
+		if !ok {
+			txHash, err := chainhash.NewHashFromStr(ins.TxId)
+			if err != nil {
+				return nil, err
+			}
+			tx, err = b.client.GetRawTransaction(txHash)
+			if err != nil {
+				return nil, err
+			}
+			txs[ins.TxId] = tx
+		}
+		owner, err := b.genesisOwner(tx.MsgTx(), ins.Offset)
+		if err != nil {
+			return nil, err
+		}
+		if owner == "" {
+			continue
+		}
+		minted[tkid] = total + amount
+
+		mints = append(mints, &tables.CBRC20Mint{
+			InscriptionId: ins.InscriptionId,
+			SequenceNum:   ins.SequenceNum,
+			Height:        height,
+			TkID:          tkid,
+			Ticker:        deploy.protocol.Ticker,
+			Amount:        amount,
+			Owner:         owner,
+			Timestamp:     ins.Timestamp,
+		})
+	}
+	return mints, nil
+}
+
+// genesisOwner return the address of the reveal tx output receiving the inscription of an envelope,
+// or empty if the inscription is sent to the fee or to an output without address.
+func (b *Runner) genesisOwner(tx *wire.MsgTx, envelope uint32) (string, error) {
+	vout, ok, err := genesisOutput(tx, envelope, func(outpoint wire.OutPoint) (int64, error) {
+		prevTx, err := b.client.GetRawTransaction(&outpoint.Hash)
+		if err != nil {
+			return 0, err
+		}
+		if outpoint.Index >= uint32(len(prevTx.MsgTx().TxOut)) {
+			return 0, fmt.Errorf("output %s not found", outpoint)
+		}
+		return prevTx.MsgTx().TxOut[outpoint.Index].Value, nil
+	})
+	if err != nil || !ok {
+		return "", err
+	}
+	pkScript, err := txscript.ParsePkScript(tx.TxOut[vout].PkScript)
+	if err != nil {
+		return "", nil
+	}
+	address, err := pkScript.Address(util.ActiveNet.Params)
+	if err != nil {
+		return "", nil
+	}
+	return address.String(), nil
+}
+
+// genesisOutput return the output of a tx receiving the inscription of an envelope, the same way as the indexer does.
+// The inscription is on the first sat of the input of its envelope, or on the sat of its pointer if the pointer
+// is below the output value. It is not in an output if the input has no value or the sat is sent to the fee.
+// inputValue return the value of a spent output.
+func genesisOutput(tx *wire.MsgTx, envelope uint32, inputValue func(wire.OutPoint) (int64, error)) (int, bool, error) {
+	input, found := uint32(0), false
+	var pointer *int64
+	forEachEnvelope(tx, func(offset, in uint32, payload [][]byte) {
+		if offset != envelope {
+			return
+		}
+		input, found = in, true
+		pointer = payloadPointer(payload)
+	})
+	if !found {
+		return 0, false, fmt.Errorf("envelope %d of %s not found", envelope, tx.TxHash())
+	}
+
+	position := uint64(0)
+	for i, in := range tx.TxIn[:input+1] {
+		value, err := inputValue(in.PreviousOutPoint)
+		if err != nil {
+			return 0, false, err
+		}
+		if i == int(input) && value == 0 {
+			return 0, false, nil
+		}
+		if i < int(input) {
+			position += uint64(value)
+		}
+	}
+	if pointer != nil && *pointer < outputsValue(tx) {
+		position = uint64(*pointer)
+	}
+	for i, out := range tx.TxOut {
+		if position < uint64(out.Value) {
+			return i, true, nil
+		}
+		position -= uint64(out.Value)
+	}
+	return 0, false, nil
+}
+
+// payloadPointer return the pointer of an envelope, the first value of the tag is used.
+// A malformed pointer is read as zero as the indexer does.
+func payloadPointer(payload [][]byte) *int64 {
+	for i := 0; i+1 < len(payload); i += 2 {
+		if len(payload[i]) == 1 && payload[i][0] == 0 {
+			break
+		}
+		if len(payload[i]) == 1 && payload[i][0] == tagPointer {
+			pointer, err := strconv.ParseInt(strings.TrimSpace(string(payload[i+1])), 10, 64)
+			if err != nil {
+				pointer = 0
+			}
+			return &pointer
+		}
+	}
+	return nil
+}
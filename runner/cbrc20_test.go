@@ -0,0 +1,90 @@
+package runner
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/inscription-c/cins/constants"
+)
+
+// testEnvelopeWitness return a script path witness holding an envelope per pointer, an empty pointer is not written.
+func testEnvelopeWitness(t *testing.T, pointers ...string) wire.TxWitness {
+	t.Helper()
+	builder := txscript.NewScriptBuilder().AddData(make([]byte, 32)).AddOp(txscript.OP_CHECKSIG)
+	for _, pointer := range pointers {
+		builder.AddOp(txscript.OP_FALSE).AddOp(txscript.OP_IF).AddData([]byte(constants.ProtocolId))
+		if pointer != "" {
+			builder.AddOp(txscript.OP_2).AddData([]byte(pointer))
+		}
+		builder.AddOp(txscript.OP_0).AddData([]byte("mint")).AddOp(txscript.OP_ENDIF)
+	}
+	script, err := builder.Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wire.TxWitness{make([]byte, 64), script, make([]byte, 33)}
+}
+
+func TestGenesisOutput(t *testing.T) {
+	values := map[wire.OutPoint]int64{
+		{Hash: chainhash.Hash{1}}: 1000,
+		{Hash: chainhash.Hash{2}}: 2000,
+		{Hash: chainhash.Hash{3}}: 0,
+	}
+	inputValue := func(outpoint wire.OutPoint) (int64, error) {
+		value, ok := values[outpoint]
+		if !ok {
+			return 0, fmt.Errorf("output %s not found", outpoint)
+		}
+		return value, nil
+	}
+	newTx := func(witnesses ...wire.TxWitness) *wire.MsgTx {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		for i, witness := range witnesses {
+			tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{byte(i + 1)}, 0), nil, witness))
+		}
+		tx.AddTxOut(wire.NewTxOut(546, nil))
+		tx.AddTxOut(wire.NewTxOut(546, nil))
+		tx.AddTxOut(wire.NewTxOut(1500, nil))
+		return tx
+	}
+
+	tests := []struct {
+		name     string
+		tx       *wire.MsgTx
+		envelope uint32
+		wantVout int
+		wantOk   bool
+		wantErr  bool
+	}{
+		{name: "first envelope", tx: newTx(testEnvelopeWitness(t, "", "546")), envelope: 0, wantVout: 0, wantOk: true},
+		{name: "pointer", tx: newTx(testEnvelopeWitness(t, "", "546")), envelope: 1, wantVout: 1, wantOk: true},
+		{name: "pointer in the middle of an output", tx: newTx(testEnvelopeWitness(t, "1100")), envelope: 0, wantVout: 2, wantOk: true},
+		{name: "pointer above the output value", tx: newTx(testEnvelopeWitness(t, "2592")), envelope: 0, wantVout: 0, wantOk: true},
+		{name: "malformed pointer", tx: newTx(testEnvelopeWitness(t, "x")), envelope: 0, wantVout: 0, wantOk: true},
+		{name: "negative pointer", tx: newTx(testEnvelopeWitness(t, "-1")), envelope: 0, wantOk: false},
+		{name: "second input", tx: newTx(nil, testEnvelopeWitness(t, "")), envelope: 0, wantVout: 1, wantOk: true},
+		{name: "input without value", tx: newTx(nil, nil, testEnvelopeWitness(t, "")), envelope: 0, wantOk: false},
+		{name: "missing envelope", tx: newTx(testEnvelopeWitness(t, "")), envelope: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vout, ok, err := genesisOutput(tt.tx, tt.envelope, inputValue)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.wantOk || (ok && vout != tt.wantVout) {
+				t.Errorf("genesisOutput(%d) = %d, %v, want %d, %v", tt.envelope, vout, ok, tt.wantVout, tt.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"github.com/btcsuite/btcd/wire"
+	"github.com/inscription-c/cins/inscription"
+	"github.com/inscription-c/cins/pkg/util"
+	"github.com/inscription-c/cins/pkg/util/txscript"
+	"github.com/inscription-c/explorer-api/tables"
+)
+
+// addChangeOutput adds an output that returns the overpayment of an order to its refund address,
+// or to its receive address if there is no refund address. The fee is computed again with the change
+// output, and the change is dropped to the fee when it's below the dust limit of the address.
+// Orders created before the fee rate was recorded have no change output.
+func addChangeOutput(revealTx *wire.MsgTx, order *tables.InscribeOrder, inputValue int64) error {
+	order.ChangeValue = 0
+	if order.FeeRate <= 0 || inputValue <= order.RevealTxValue {
+		return nil
+	}
+
+	changeAddress := order.ReceiveAddress
+	if order.RefundAddress != "" {
+		changeAddress = order.RefundAddress
+	}
+	changePkScript, err := util.AddressScript(changeAddress, util.ActiveNet.Params)
+	if err != nil {
+		return err
+	}
+
+	outputsValue := int64(0)
+	for _, txOut := range revealTx.TxOut {
+		outputsValue += txOut.Value
+	}
+	revealTx.AddTxOut(wire.NewTxOut(0, changePkScript))
+	change := inputValue - outputsValue - inscription.CalculateTxFee(revealTx, order.FeeRate)
+	if change < dustLimit(changePkScript) {
+		revealTx.TxOut = revealTx.TxOut[:len(revealTx.TxOut)-1]
+		return nil
+	}
+	revealTx.TxOut[len(revealTx.TxOut)-1].Value = change
+	order.ChangeValue = change
+	return nil
+}
+
+// dustLimit return the min value of an output by its script type at the default min relay fee.
+func dustLimit(pkScript []byte) int64 {
+	switch txscript.GetScriptClass(pkScript) {
+	case txscript.WitnessV0PubKeyHashTy:
+		return 294
+	case txscript.WitnessV0ScriptHashTy, txscript.WitnessV1TaprootTy:
+		return 330
+	case txscript.ScriptHashTy:
+		return 540
+	default:
+		return 546
+	}
+}
@@ -0,0 +1,117 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/inscription-c/cins/constants"
+	"github.com/inscription-c/cins/pkg/util"
+	"github.com/inscription-c/explorer-api/tables"
+)
+
+// testTaprootAddress return a taproot address of the active network for a key made of a repeated byte.
+func testTaprootAddress(t *testing.T, b byte) string {
+	t.Helper()
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = b
+	}
+	priKey, _ := btcec.PrivKeyFromBytes(key)
+	outputKey := txscript.ComputeTaprootKeyNoScript(priKey.PubKey())
+	address, err := btcutil.NewAddressTaproot(schnorr.SerializePubKey(outputKey), util.ActiveNet.Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return address.EncodeAddress()
+}
+
+// testRevealTx return a tx that spends one input by the script path to the postage output of an order,
+// the reveal tx value of the order is set to the postage and the fee of the tx.
+func testRevealTx(t *testing.T, order *tables.InscribeOrder) *wire.MsgTx {
+	t.Helper()
+	pkScript, err := util.AddressScript(order.ReceiveAddress, util.ActiveNet.Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil,
+		wire.TxWitness{make([]byte, 64), make([]byte, 100), make([]byte, 33)}))
+	tx.AddTxOut(wire.NewTxOut(testPostage, pkScript))
+	order.RevealTxValue = testPostage + testTxFee(tx, order.FeeRate)
+	return tx
+}
+
+const testPostage = 546
+
+// testTxFee return the fee of a tx at a fee rate in sat/kvB, cins pays at least the dust limit as the fee.
+func testTxFee(tx *wire.MsgTx, feeRate int64) int64 {
+	weight := int64(tx.SerializeSizeStripped()*3 + tx.SerializeSize())
+	fee := weight * feeRate / 4000
+	if fee < constants.DustLimit {
+		fee = constants.DustLimit
+	}
+	return fee
+}
+
+func TestAddChangeOutput(t *testing.T) {
+	receiveAddress := testTaprootAddress(t, 1)
+	refundAddress := testTaprootAddress(t, 2)
+
+	tests := []struct {
+		name          string
+		feeRate       int64
+		refundAddress string
+		overpayment   int64
+		wantChange    bool
+		wantAddress   string
+	}{
+		{name: "exact payment", feeRate: 10000},
+		{name: "legacy order without fee rate", feeRate: 0, overpayment: 50000},
+		{name: "change below dust", feeRate: 10000, overpayment: 700},
+		{name: "change to receive address", feeRate: 10000, overpayment: 50000, wantChange: true, wantAddress: receiveAddress},
+		{name: "change to refund address", feeRate: 10000, refundAddress: refundAddress, overpayment: 50000,
+			wantChange: true, wantAddress: refundAddress},
+		{name: "fractional fee rate", feeRate: 25500, overpayment: 50000, wantChange: true, wantAddress: receiveAddress},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &tables.InscribeOrder{
+				FeeRate:        tt.feeRate,
+				ReceiveAddress: receiveAddress,
+				RefundAddress:  tt.refundAddress,
+			}
+			revealTx := testRevealTx(t, order)
+			inputValue := order.RevealTxValue + tt.overpayment
+			if err := addChangeOutput(revealTx, order, inputValue); err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.wantChange {
+				if len(revealTx.TxOut) != 1 || order.ChangeValue != 0 {
+					t.Fatalf("got %d outputs and change %d, want no change", len(revealTx.TxOut), order.ChangeValue)
+				}
+				return
+			}
+			if len(revealTx.TxOut) != 2 {
+				t.Fatalf("got %d outputs, want 2", len(revealTx.TxOut))
+			}
+			changeOut := revealTx.TxOut[1]
+			wantPkScript, err := util.AddressScript(tt.wantAddress, util.ActiveNet.Params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(changeOut.PkScript) != string(wantPkScript) {
+				t.Errorf("change is sent to the wrong address")
+			}
+			wantChange := inputValue - revealTx.TxOut[0].Value - testTxFee(revealTx, tt.feeRate)
+			if changeOut.Value != wantChange || order.ChangeValue != wantChange {
+				t.Errorf("change = %d, order change = %d, want %d", changeOut.Value, order.ChangeValue, wantChange)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package runner
+
+import (
+	"github.com/inscription-c/cins/pkg/signal"
+	"github.com/inscription-c/explorer-api/log"
+	"time"
+)
+
+// parsedBlock is a block parsed by a blockFollower, its hash is kept to detect reorgs.
+type parsedBlock struct {
+	Height    uint32
+	BlockHash string
+}
+
+// blockFollower parses the blocks of the indexer in order. When the indexer reorgs, the parsed data
+// after the fork point is deleted and the blocks are parsed again in the next round.
+type blockFollower struct {
+	// name is the name of the parser in the logs.
+	name string
+	// last return the last parsed block, the block hash is empty if no block is parsed.
+	last func() (*parsedBlock, error)
+	// parsed return the kept parsed blocks in descending order of height.
+	parsed func() ([]*parsedBlock, error)
+	// parse parses a block and saves the parsed data with the block.
+	parse func(block *parsedBlock) error
+	// deleteFrom deletes the parsed data and the parsed blocks from a height.
+	deleteFrom func(height uint32) error
+}
+
+// followBlocks runs a follower every 5 seconds, newFollower is called each round so that a follower
+// can keep state during a round.
+func (b *Runner) followBlocks(newFollower func() *blockFollower) {
+	b.Go(func() error {
+		for {
+			time.Sleep(time.Second * 5)
+			select {
+			case <-signal.InterruptChannel:
+				return nil
+			default:
+				f := newFollower()
+				if err := b.follow(f); err != nil {
+					log.Log.Errorf("%s parser err: %s", f.name, err)
+				}
+			}
+		}
+	})
+}
+
+// follow parses the blocks of the indexer after the last parsed block.
+func (b *Runner) follow(f *blockFollower) error {
+	last, err := f.last()
+	if err != nil {
+		return err
+	}
+	latest, err := b.indexerDB.LatestBlockInfo()
+	if err != nil {
+		return err
+	}
+	if latest.Id == 0 {
+		return nil
+	}
+
+	height := last.Height + 1
+	if last.BlockHash == "" {
+		first, err := b.indexerDB.FirstBlockInfo()
+		if err != nil {
+			return err
+		}
+		height = first.Height
+	}
+
+	for ; height <= latest.Height; height++ {
+		select {
+		case <-signal.InterruptChannel:
+			return nil
+		default:
+		}
+
+		block, err := b.indexerDB.GetBlockInfoByHeight(height)
+		if err != nil {
+			return err
+		}
+		if block.Id == 0 {
+			return nil
+		}
+		header, err := block.LoadHeader()
+		if err != nil {
+			return err
+		}
+		if last.BlockHash != "" && header.PrevBlock.String() != last.BlockHash {
+			return b.rollback(f)
+		}
+
+		parsed := &parsedBlock{
+			Height:    height,
+			BlockHash: header.BlockHash().String(),
+		}
+		if err := f.parse(parsed); err != nil {
+			return err
+		}
+		last = parsed
+	}
+	return nil
+}
+
+// rollback deletes the parsed data after the fork point of a reorg, the blocks are parsed again in the next round.
+func (b *Runner) rollback(f *blockFollower) error {
+	list, err := f.parsed()
+	if err != nil {
+		return err
+	}
+	forkHeight := uint32(0)
+	for _, parsed := range list {
+		block, err := b.indexerDB.GetBlockInfoByHeight(parsed.Height)
+		if err != nil {
+			return err
+		}
+		if block.Id == 0 {
+			continue
+		}
+		header, err := block.LoadHeader()
+		if err != nil {
+			return err
+		}
+		if header.BlockHash().String() == parsed.BlockHash {
+			forkHeight = parsed.Height + 1
+			break
+		}
+	}
+	if forkHeight == 0 {
+		log.Log.Warnf("%s fork point not found, parse all blocks again", f.name)
+	}
+	log.Log.Infof("%s rolling back from height %d", f.name, forkHeight)
+	return f.deleteFrom(forkHeight)
+}
@@ -0,0 +1,198 @@
+package runner
+
+import (
+	"errors"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/inscription-c/cins/pkg/signal"
+	"github.com/inscription-c/cins/pkg/util"
+	"github.com/inscription-c/cins/pkg/util/txscript"
+	"github.com/inscription-c/explorer-api/log"
+	"github.com/inscription-c/explorer-api/tables"
+	"time"
+)
+
+// WatchMempool polls the mempool for commit txs of the orders waiting for payment, so that the orders
+// don't wait for a block to show the payment. The block parser still records the payments once they're confirmed.
+func (b *Runner) WatchMempool() {
+	b.Go(func() error {
+		seen := make(map[chainhash.Hash]struct{})
+		ticker := time.NewTicker(time.Second * 5)
+		defer ticker.Stop()
+		for range ticker.C {
+			select {
+			case <-signal.InterruptChannel:
+				return nil
+			default:
+				if err := b.watchMempool(seen); err != nil {
+					log.Log.Errorf("watchMempool err: %s", err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// watchMempool matches the outputs of the mempool txs that are not seen before to the reveal addresses of the
+// waiting orders, and moves the orders with a commit tx that left the mempool unconfirmed back to waiting.
+func (b *Runner) watchMempool(seen map[chainhash.Hash]struct{}) error {
+	// the mempool is fetched before the orders, a tx in it can't pay an order created later
+	txHashes, err := b.client.GetRawMempool()
+	if err != nil {
+		return err
+	}
+	orders, err := b.db.FindMempoolWatchedInscribeOrders()
+	if err != nil {
+		return err
+	}
+
+	mempool := make(map[chainhash.Hash]struct{}, len(txHashes))
+	for _, txHash := range txHashes {
+		mempool[*txHash] = struct{}{}
+	}
+
+	waiting := make(map[string]*tables.InscribeOrder)
+	for _, order := range orders {
+		if order.Status == tables.OrderStatusCommitSeen {
+			// a commit tx that replaced the one that left the mempool is matched below
+			left, err := b.checkMempoolCommitTx(order, mempool)
+			if err != nil {
+				return err
+			}
+			if !left {
+				continue
+			}
+		}
+		waiting[order.RevealAddress] = order
+	}
+
+	for _, txHash := range txHashes {
+		if _, ok := seen[*txHash]; ok || len(waiting) == 0 {
+			continue
+		}
+		tx, err := b.client.GetRawTransaction(txHash)
+		var jsonErr *btcjson.RPCError
+		if errors.As(err, &jsonErr) && jsonErr.Code == btcjson.ErrRPCNoTxInfo {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		for idx, txOut := range tx.MsgTx().TxOut {
+			pkScript, err := txscript.ParsePkScript(txOut.PkScript)
+			if err != nil {
+				continue
+			}
+			address, err := pkScript.Address(util.ActiveNet.Params)
+			if err != nil {
+				continue
+			}
+			order, ok := waiting[address.String()]
+			if !ok {
+				continue
+			}
+			delete(waiting, address.String())
+
+			order.Status = tables.OrderStatusCommitSeen
+			order.CommitTxId = txHash.String()
+			order.CommitTxIndex = uint32(idx)
+			order.CommitTxValue = txOut.Value
+			if b.mempoolReveal && txOut.Value >= order.RevealTxValue {
+				if err := b.sendMempoolRevealTx(order); err != nil {
+					log.Log.Error("MempoolRevealTxSendError", err, order.OrderId)
+				}
+			}
+			updated, err := b.db.SetInscribeOrderCommitSeen(order)
+			if err != nil {
+				return err
+			}
+			if !updated {
+				continue
+			}
+			if err := b.db.CreateInscribeOrderEvent(&tables.InscribeOrderEvent{
+				OrderId: order.OrderId,
+				Type:    tables.OrderEventCommitSeen,
+				Status:  tables.OrderStatusCommitSeen,
+				TxId:    order.CommitTxId,
+				Amount:  order.CommitTxValue,
+			}); err != nil {
+				return err
+			}
+			if order.RevealTxId != "" {
+				if err := b.db.CreateInscribeOrderEvent(&tables.InscribeOrderEvent{
+					OrderId: order.OrderId,
+					Type:    tables.OrderEventRevealSent,
+					Status:  tables.OrderStatusCommitSeen,
+					TxId:    order.RevealTxId,
+					Amount:  order.CommitTxValue,
+				}); err != nil {
+					return err
+				}
+			}
+			log.Log.Infof("CommitTxSeen %s %s %d", order.OrderId, order.CommitTxId, order.CommitTxValue)
+		}
+	}
+
+	// the seen txs are kept while they're in the mempool
+	for txHash := range seen {
+		if _, ok := mempool[txHash]; !ok {
+			delete(seen, txHash)
+		}
+	}
+	for txHash := range mempool {
+		seen[txHash] = struct{}{}
+	}
+	return nil
+}
+
+// checkMempoolCommitTx moves an order back to waiting when its commit tx was replaced or evicted from the mempool,
+// and return whether it did. A commit tx that left the mempool because it's confirmed is left to the block parser.
+func (b *Runner) checkMempoolCommitTx(order *tables.InscribeOrder, mempool map[chainhash.Hash]struct{}) (bool, error) {
+	commitTxHash, err := chainhash.NewHashFromStr(order.CommitTxId)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := mempool[*commitTxHash]; ok {
+		return false, nil
+	}
+	_, err = b.client.GetRawTransactionVerbose(commitTxHash)
+	var jsonErr *btcjson.RPCError
+	if err == nil || !errors.As(err, &jsonErr) || jsonErr.Code != btcjson.ErrRPCNoTxInfo {
+		return false, err
+	}
+
+	log.Log.Warn("CommitTxLeftMempool", order.OrderId, order.CommitTxId)
+	updated, err := b.db.ResetInscribeOrderCommitSeen(order)
+	if err != nil || !updated {
+		return false, err
+	}
+	order.Status = tables.OrderStatusDefault
+	order.CommitTxId = ""
+	order.CommitTxIndex = 0
+	order.CommitTxValue = 0
+	order.RevealTxId = ""
+	return true, nil
+}
+
+// sendMempoolRevealTx sends the reveal tx as a child of the unconfirmed commit tx of an order.
+// The signed reveal tx isn't saved, the block parser signs the same tx again once the commit tx is confirmed.
+func (b *Runner) sendMempoolRevealTx(order *tables.InscribeOrder) error {
+	payment := &tables.InscribeOrderPayment{
+		OrderId: order.OrderId,
+		TxId:    order.CommitTxId,
+		Index:   order.CommitTxIndex,
+		Value:   order.CommitTxValue,
+	}
+	signed := *order
+	revealTx, err := b.signRevealTx(&signed, []*tables.InscribeOrderPayment{payment})
+	if err != nil {
+		return err
+	}
+	if _, err := b.client.SendRawTransaction(revealTx, false); err != nil {
+		return err
+	}
+	order.RevealTxId = revealTx.TxHash().String()
+	log.Log.Infof("MempoolRevealTxSendSuccess %s %s", order.OrderId, order.RevealTxId)
+	return nil
+}
@@ -0,0 +1,330 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	cinsConstants "github.com/inscription-c/cins/constants"
+	"github.com/inscription-c/cins/inscription/index"
+	"github.com/inscription-c/explorer-api/constants"
+	"github.com/inscription-c/explorer-api/dao"
+	"github.com/inscription-c/explorer-api/log"
+	"github.com/inscription-c/explorer-api/tables"
+	"strconv"
+	"strings"
+)
+
+// parentKeepBlocks is the number of parsed blocks kept to find the fork point of a reorg.
+const parentKeepBlocks uint32 = 50
+
+// tagParent is the envelope tag of the parent inscription id.
+const tagParent = 3
+
+// parentSpends are the txs that spent the outputs holding a parent, traced from its satpoint.
+type parentSpends struct {
+	satPoint string
+	txIds    map[string]bool
+}
+
+// ParentParser follows the blocks of the indexer and records the parents of the inscriptions,
+// which the indexer doesn't keep.
+func (b *Runner) ParentParser() {
+	b.followBlocks(b.parentFollower)
+}
+
+// parentFollower return the follower of the parents, the spends of the parents are cached during a round.
+func (b *Runner) parentFollower() *blockFollower {
+	spends := make(map[int64]*parentSpends)
+	return &blockFollower{
+		name: "Parents",
+		last: func() (*parsedBlock, error) {
+			info, err := b.db.LastParentParserInfo()
+			return &parsedBlock{Height: info.Height, BlockHash: info.BlockHash}, err
+		},
+		parsed: func() ([]*parsedBlock, error) {
+			infos, err := b.db.FindParentParserInfos()
+			list := make([]*parsedBlock, 0, len(infos))
+			for _, info := range infos {
+				list = append(list, &parsedBlock{Height: info.Height, BlockHash: info.BlockHash})
+			}
+			return list, err
+		},
+		parse: func(block *parsedBlock) error {
+			parents, err := b.parseParents(block.Height, spends)
+			if err != nil {
+				return err
+			}
+			info := &tables.ParentParserInfo{
+				Height:    block.Height,
+				BlockHash: block.BlockHash,
+			}
+			if err := b.db.Transaction(func(wtx *dao.DB) error {
+				return wtx.CreateParentBlock(info, parents, parentKeepBlocks)
+			}); err != nil {
+				return err
+			}
+			if len(parents) > 0 {
+				log.Log.Infof("Parents height: %d children: %d", block.Height, len(parents))
+			}
+			return nil
+		},
+		deleteFrom: func(height uint32) error {
+			return b.db.Transaction(func(wtx *dao.DB) error {
+				return wtx.DeleteParentsFrom(height)
+			})
+		},
+	}
+}
+
+// parseParents return the parents of the inscriptions revealed in a block.
+// A parent is valid if it was inscribed before the child and the reveal tx of the child spent the output
+// holding the parent at that time. The spends of a parent are cached by its satpoint in spends.
+func (b *Runner) parseParents(height uint32, spends map[int64]*parentSpends) ([]*tables.InscriptionParent, error) {
+	list, err := b.indexerDB.FindInscriptionSequencesInBlock(height)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make(map[string]*btcutil.Tx)
+	parents := make([]*tables.InscriptionParent, 0)
+	for _, ins := range list {
+		tx, ok := txs[ins.TxId]
+		if !ok {
+			txHash, err := chainhash.NewHashFromStr(ins.TxId)
+			if err != nil {
+				return nil, err
+			}
+			tx, err = b.client.GetRawTransaction(txHash)
+			if err != nil {
+				return nil, err
+			}
+			txs[ins.TxId] = tx
+		}
+
+		parentId := envelopeParents(tx.MsgTx())[ins.Offset]
+		if parentId == nil {
+			continue
+		}
+		parent, err := b.indexerDB.GetInscriptionById(parentId)
+		if err != nil {
+			return nil, err
+		}
+		if parent.Id == 0 || parent.SequenceNum >= ins.SequenceNum {
+			continue
+		}
+		satPoint, err := b.indexerDB.GetSatPointBySequenceNum(parent.SequenceNum)
+		if err != nil {
+			return nil, err
+		}
+		if satPoint.Id == 0 {
+			continue
+		}
+		spend, ok := spends[parent.SequenceNum]
+		if !ok || spend.satPoint != satPoint.String() {
+			txIds, err := b.inscriptionSpends(&parent.InscriptionId, &satPoint)
+			if err != nil {
+				return nil, err
+			}
+			spend = &parentSpends{satPoint: satPoint.String(), txIds: txIds}
+			spends[parent.SequenceNum] = spend
+		}
+		if !spend.txIds[ins.TxId] {
+			continue
+		}
+		parents = append(parents, &tables.InscriptionParent{
+			InscriptionId: ins.InscriptionId,
+			SequenceNum:   ins.SequenceNum,
+			ParentId:      parentId.String(),
+			Height:        height,
+		})
+	}
+	return parents, nil
+}
+
+// inscriptionSpends return the txs that spent the outputs holding an inscription. Its sat is traced back from
+// its current satpoint to its reveal tx, through the input of each tx the sat came from, so that the past
+// locations of the inscription are known after it moved. The trace stops at a coinbase tx if the inscription
+// was paid as fee, the spends before it are not found then.
+func (b *Runner) inscriptionSpends(inscriptionId *tables.InscriptionId, satPoint *tables.SatPointToSequenceNum) (map[string]bool, error) {
+	spends := make(map[string]bool)
+	txId, indexStr, ok := strings.Cut(satPoint.Outpoint, constants.OutpointDelimiter)
+	if !ok {
+		return nil, fmt.Errorf("invalid outpoint %s", satPoint.Outpoint)
+	}
+	vout, err := strconv.ParseUint(indexStr, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid outpoint %s", satPoint.Outpoint)
+	}
+	offset := satPoint.Offset
+
+	for txId != inscriptionId.TxId {
+		txHash, err := chainhash.NewHashFromStr(txId)
+		if err != nil {
+			return nil, err
+		}
+		if *txHash == (chainhash.Hash{}) {
+			return spends, nil
+		}
+		tx, err := b.client.GetRawTransaction(txHash)
+		if err != nil {
+			return nil, err
+		}
+		msgTx := tx.MsgTx()
+		if blockchain.IsCoinBaseTx(msgTx) {
+			return spends, nil
+		}
+		spends[txId] = true
+
+		prevOut, prevOffset, err := satInput(msgTx, uint32(vout), offset, func(outpoint wire.OutPoint) (int64, error) {
+			prevTx, err := b.client.GetRawTransaction(&outpoint.Hash)
+			if err != nil {
+				return 0, err
+			}
+			if outpoint.Index >= uint32(len(prevTx.MsgTx().TxOut)) {
+				return 0, fmt.Errorf("output %s not found", outpoint)
+			}
+			return prevTx.MsgTx().TxOut[outpoint.Index].Value, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("trace %s: %w", inscriptionId, err)
+		}
+		txId, vout, offset = prevOut.Hash.String(), uint64(prevOut.Index), prevOffset
+	}
+	return spends, nil
+}
+
+// satInput return the spent output and the offset in it of the sat at an offset of an output of a tx,
+// the sats of the inputs flow to the outputs in order. inputValue return the value of a spent output.
+func satInput(tx *wire.MsgTx, vout uint32, offset uint64, inputValue func(wire.OutPoint) (int64, error)) (wire.OutPoint, uint64, error) {
+	if vout >= uint32(len(tx.TxOut)) {
+		return wire.OutPoint{}, 0, fmt.Errorf("output %d of %s not found", vout, tx.TxHash())
+	}
+	position := offset
+	for _, out := range tx.TxOut[:vout] {
+		position += uint64(out.Value)
+	}
+	for _, in := range tx.TxIn {
+		value, err := inputValue(in.PreviousOutPoint)
+		if err != nil {
+			return wire.OutPoint{}, 0, err
+		}
+		if position < uint64(value) {
+			return in.PreviousOutPoint, position, nil
+		}
+		position -= uint64(value)
+	}
+	return wire.OutPoint{}, 0, fmt.Errorf("sat %d of output %d not found in the inputs of %s", offset, vout, tx.TxHash())
+}
+
+// envelopeParents return the parent inscription ids of a tx by envelope offset.
+func envelopeParents(tx *wire.MsgTx) map[uint32]*tables.InscriptionId {
+	parents := make(map[uint32]*tables.InscriptionId)
+	forEachEnvelope(tx, func(offset, _ uint32, payload [][]byte) {
+		if parentId := payloadParent(payload); parentId != nil {
+			parents[offset] = parentId
+		}
+	})
+	return parents
+}
+
+// forEachEnvelope calls fn with the offset, the input index and the payload of the envelopes of a tx.
+// The envelopes are parsed the same way as the indexer does, so that the offsets match the inscription ids.
+func forEachEnvelope(tx *wire.MsgTx, fn func(offset, input uint32, payload [][]byte)) {
+	offset := uint32(0)
+	for i, input := range tx.TxIn {
+		w := &index.Witness{TxWitness: input.Witness}
+		if !w.IsTaprootScript() {
+			continue
+		}
+		tokenizer := w.ScriptTokenizer()
+		for tokenizer.Next() {
+			payload, ok := envelopePayload(tokenizer)
+			if !ok {
+				continue
+			}
+			fn(offset, uint32(i), payload)
+			offset++
+		}
+	}
+}
+
+// envelopePayload reads the pushes of an envelope starting at the current instruction.
+func envelopePayload(tokenizer *txscript.ScriptTokenizer) ([][]byte, bool) {
+	if tokenizer.Opcode() != txscript.OP_IF {
+		return nil, false
+	}
+	if !tokenizer.Next() || !bytes.Equal(tokenizer.Data(), []byte(cinsConstants.ProtocolId)) {
+		return nil, false
+	}
+
+	payload := make([][]byte, 0)
+	for tokenizer.Next() {
+		opcode := tokenizer.Opcode()
+		switch {
+		case opcode == txscript.OP_ENDIF:
+			return payload, true
+		case opcode == txscript.OP_1NEGATE:
+			payload = append(payload, []byte{0x81})
+		case opcode == txscript.OP_0:
+			payload = append(payload, []byte{0})
+		case opcode >= txscript.OP_1 && opcode <= txscript.OP_16:
+			payload = append(payload, []byte{opcode - txscript.OP_1 + 1})
+		case isPushBytes(opcode):
+			payload = append(payload, tokenizer.Data())
+		default:
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// payloadParent return the parent of an envelope, if the tag is present once and is well-formed.
+func payloadParent(payload [][]byte) *tables.InscriptionId {
+	var value []byte
+	found := 0
+	for i := 0; i+1 < len(payload); i += 2 {
+		if len(payload[i]) == 1 && payload[i][0] == 0 {
+			break
+		}
+		if len(payload[i]) > 0 && payload[i][0] == tagParent {
+			value = payload[i+1]
+			found++
+		}
+	}
+	if found != 1 {
+		return nil
+	}
+	return decodeInscriptionId(value)
+}
+
+// decodeInscriptionId decodes an inscription id from a 32 byte txid followed by
+// the little endian offset without trailing zeros.
+func decodeInscriptionId(value []byte) *tables.InscriptionId {
+	if len(value) < chainhash.HashSize || len(value) > chainhash.HashSize+4 {
+		return nil
+	}
+	txid, err := chainhash.NewHash(value[:chainhash.HashSize])
+	if err != nil {
+		return nil
+	}
+	offsetBytes := value[chainhash.HashSize:]
+	if len(offsetBytes) > 0 && offsetBytes[len(offsetBytes)-1] == 0 {
+		return nil
+	}
+	offset := uint32(0)
+	for i, v := range offsetBytes {
+		offset |= uint32(v) << (8 * i)
+	}
+	return tables.NewInscriptionId(txid.String(), offset)
+}
+
+// isPushBytes checks if the opcode pushes data.
+func isPushBytes(opcode byte) bool {
+	return (opcode >= txscript.OP_DATA_1 && opcode <= txscript.OP_DATA_75) ||
+		opcode == txscript.OP_PUSHDATA1 || opcode == txscript.OP_PUSHDATA2 ||
+		opcode == txscript.OP_PUSHDATA4
+}
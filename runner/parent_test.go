@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+func TestSatInput(t *testing.T) {
+	prevOut := func(b byte, index uint32) wire.OutPoint {
+		return wire.OutPoint{Hash: chainhash.Hash{b}, Index: index}
+	}
+	values := map[wire.OutPoint]int64{
+		prevOut(1, 0): 1000,
+		prevOut(2, 3): 546,
+		prevOut(3, 1): 5000,
+	}
+	inputValue := func(outpoint wire.OutPoint) (int64, error) {
+		value, ok := values[outpoint]
+		if !ok {
+			return 0, fmt.Errorf("output %s not found", outpoint)
+		}
+		return value, nil
+	}
+	tx := wire.NewMsgTx(wire.TxVersion)
+	for _, outpoint := range []wire.OutPoint{prevOut(1, 0), prevOut(2, 3), prevOut(3, 1)} {
+		outpoint := outpoint
+		tx.AddTxIn(wire.NewTxIn(&outpoint, nil, nil))
+	}
+	tx.AddTxOut(wire.NewTxOut(546, nil))
+	tx.AddTxOut(wire.NewTxOut(1500, nil))
+	tx.AddTxOut(wire.NewTxOut(4000, nil))
+
+	tests := []struct {
+		name       string
+		vout       uint32
+		offset     uint64
+		wantOut    wire.OutPoint
+		wantOffset uint64
+		wantErr    bool
+	}{
+		{name: "first sat", vout: 0, offset: 0, wantOut: prevOut(1, 0), wantOffset: 0},
+		{name: "second output in first input", vout: 1, offset: 0, wantOut: prevOut(1, 0), wantOffset: 546},
+		{name: "last sat of first input", vout: 1, offset: 453, wantOut: prevOut(1, 0), wantOffset: 999},
+		{name: "second input", vout: 1, offset: 454, wantOut: prevOut(2, 3), wantOffset: 0},
+		{name: "third input", vout: 2, offset: 0, wantOut: prevOut(3, 1), wantOffset: 500},
+		{name: "last sat", vout: 2, offset: 3999, wantOut: prevOut(3, 1), wantOffset: 4499},
+		{name: "missing output", vout: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, offset, err := satInput(tx, tt.vout, tt.offset, inputValue)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out != tt.wantOut || offset != tt.wantOffset {
+				t.Errorf("satInput(%d, %d) = %s, %d, want %s, %d", tt.vout, tt.offset, out, offset, tt.wantOut, tt.wantOffset)
+			}
+		})
+	}
+}
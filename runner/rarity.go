@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"github.com/inscription-c/cins/inscription/index"
+	"github.com/inscription-c/explorer-api/dao"
+	"github.com/inscription-c/explorer-api/log"
+	"github.com/inscription-c/explorer-api/tables"
+)
+
+// rarityKeepBlocks is the number of parsed blocks kept to find the fork point of a reorg.
+const rarityKeepBlocks uint32 = 50
+
+// RarityParser follows the blocks of the indexer and records the rarities of the sats of the inscriptions.
+func (b *Runner) RarityParser() {
+	b.followBlocks(b.rarityFollower)
+}
+
+// rarityFollower return the follower of the rarities.
+func (b *Runner) rarityFollower() *blockFollower {
+	return &blockFollower{
+		name: "Rarities",
+		last: func() (*parsedBlock, error) {
+			info, err := b.db.LastRarityParserInfo()
+			return &parsedBlock{Height: info.Height, BlockHash: info.BlockHash}, err
+		},
+		parsed: func() ([]*parsedBlock, error) {
+			infos, err := b.db.FindRarityParserInfos()
+			list := make([]*parsedBlock, 0, len(infos))
+			for _, info := range infos {
+				list = append(list, &parsedBlock{Height: info.Height, BlockHash: info.BlockHash})
+			}
+			return list, err
+		},
+		parse: func(block *parsedBlock) error {
+			rarities, err := b.parseRarities(block.Height)
+			if err != nil {
+				return err
+			}
+			info := &tables.RarityParserInfo{
+				Height:    block.Height,
+				BlockHash: block.BlockHash,
+			}
+			if err := b.db.Transaction(func(wtx *dao.DB) error {
+				return wtx.CreateRarityBlock(info, rarities, rarityKeepBlocks)
+			}); err != nil {
+				return err
+			}
+			if len(rarities) > 0 {
+				log.Log.Infof("Rarities height: %d uncommon or better: %d", block.Height, len(rarities))
+			}
+			return nil
+		},
+		deleteFrom: func(height uint32) error {
+			return b.db.Transaction(func(wtx *dao.DB) error {
+				return wtx.DeleteRaritiesFrom(height)
+			})
+		},
+	}
+}
+
+// parseRarities return the rarities of the inscriptions in a block that are not on common sats.
+func (b *Runner) parseRarities(height uint32) ([]*tables.InscriptionRarity, error) {
+	list, err := b.indexerDB.FindInscriptionSequencesInBlock(height)
+	if err != nil {
+		return nil, err
+	}
+
+	rarities := make([]*tables.InscriptionRarity, 0)
+	for _, ins := range list {
+		// the indexer leaves the sat at 0 when the inscription is unbound or the sats aren't indexed,
+		// sat 0 is the unspendable genesis coinbase sat and never carries an inscription.
+		if ins.Sat == 0 {
+			continue
+		}
+		sat := index.Sat(ins.Sat)
+		rarity := sat.Rarity()
+		if rarity == index.RarityCommon {
+			continue
+		}
+		rarities = append(rarities, &tables.InscriptionRarity{
+			SequenceNum: ins.SequenceNum,
+			Rarity:      uint8(rarity),
+			Height:      height,
+		})
+	}
+	return rarities, nil
+}
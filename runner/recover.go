@@ -0,0 +1,158 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/inscription-c/cins/inscription"
+	"github.com/inscription-c/cins/pkg/util"
+	"github.com/inscription-c/explorer-api/tables"
+)
+
+// RecoverOrders checks that the reveal keys of orders derived from the HD key match their reveal addresses,
+// and return the outputs to the reveal addresses that are not spent yet by order id, as found by one scan of
+// the UTXO set of the node. The reveal address commits to the reveal script, so the reveal tx of the order stored
+// in the DB is needed besides the HD key, the address can't be derived from the key alone.
+func (b *Runner) RecoverOrders(orders []*tables.InscribeOrder) (map[string][]*tables.InscribeOrderPayment, error) {
+	for _, order := range orders {
+		if err := b.checkRevealKey(order); err != nil {
+			return nil, fmt.Errorf("order %s: %w", order.OrderId, err)
+		}
+	}
+	return b.scanUnspent(orders)
+}
+
+// checkRevealKey checks that the reveal key of an order derived from the HD key matches its reveal address.
+func (b *Runner) checkRevealKey(order *tables.InscribeOrder) error {
+	priKey, err := b.orderPriKey(order)
+	if err != nil {
+		return err
+	}
+	revealTxData, err := hex.DecodeString(order.RevealTxRaw)
+	if err != nil {
+		return err
+	}
+	revealTx := &wire.MsgTx{}
+	if err := revealTx.Deserialize(bytes.NewReader(revealTxData)); err != nil {
+		return err
+	}
+	_, address, err := inscription.RevealScriptAddress(priKey.PubKey(), revealTx.TxIn[0].Witness[1])
+	if err != nil {
+		return err
+	}
+	if address.String() != order.RevealAddress {
+		return fmt.Errorf("key of %s doesn't match the reveal address %s", order.RevealKeyPath, order.RevealAddress)
+	}
+	return nil
+}
+
+// scanTxOutSetResult is the result of the scantxoutset rpc.
+type scanTxOutSetResult struct {
+	Success  bool `json:"success"`
+	Unspents []struct {
+		TxId         string  `json:"txid"`
+		Vout         uint32  `json:"vout"`
+		ScriptPubKey string  `json:"scriptPubKey"`
+		Amount       float64 `json:"amount"`
+		Height       uint32  `json:"height"`
+	} `json:"unspents"`
+}
+
+// scanUnspent return the confirmed outputs to the reveal addresses of orders by order id from the UTXO set
+// of the node, so that the payments the parser missed are found too. The addresses are scanned at once,
+// as a scan reads the whole UTXO set.
+func (b *Runner) scanUnspent(orders []*tables.InscribeOrder) (map[string][]*tables.InscribeOrderPayment, error) {
+	if len(orders) == 0 {
+		return map[string][]*tables.InscribeOrderPayment{}, nil
+	}
+	scripts := make(map[string]string, len(orders))
+	descriptors := make([]map[string]string, 0, len(orders))
+	for _, order := range orders {
+		pkScript, err := util.AddressScript(order.RevealAddress, util.ActiveNet.Params)
+		if err != nil {
+			return nil, fmt.Errorf("order %s: %w", order.OrderId, err)
+		}
+		scripts[hex.EncodeToString(pkScript)] = order.OrderId
+		descriptors = append(descriptors, map[string]string{"desc": fmt.Sprintf("addr(%s)", order.RevealAddress)})
+	}
+	params, err := json.Marshal(descriptors)
+	if err != nil {
+		return nil, err
+	}
+	res, err := b.client.RawRequest("scantxoutset", []json.RawMessage{json.RawMessage(`"start"`), params})
+	if err != nil {
+		return nil, err
+	}
+	return parseScanTxOutSet(scripts, res)
+}
+
+// parseScanTxOutSet converts the unspent outputs of a scantxoutset result to payments by order id,
+// the order of an output is found by its script in scripts.
+func parseScanTxOutSet(scripts map[string]string, res json.RawMessage) (map[string][]*tables.InscribeOrderPayment, error) {
+	result := &scanTxOutSetResult{}
+	if err := json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	if !result.Success {
+		return nil, errors.New("scantxoutset failed")
+	}
+	payments := make(map[string][]*tables.InscribeOrderPayment)
+	for _, unspent := range result.Unspents {
+		orderId, ok := scripts[unspent.ScriptPubKey]
+		if !ok {
+			return nil, fmt.Errorf("unspent output %s:%d of an unknown script %s", unspent.TxId, unspent.Vout, unspent.ScriptPubKey)
+		}
+		value, err := btcutil.NewAmount(unspent.Amount)
+		if err != nil {
+			return nil, err
+		}
+		payments[orderId] = append(payments[orderId], &tables.InscribeOrderPayment{
+			OrderId: orderId,
+			TxId:    unspent.TxId,
+			Index:   unspent.Vout,
+			Value:   int64(value),
+			Height:  unspent.Height,
+		})
+	}
+	return payments, nil
+}
+
+// sweepable return whether the payments of an order in a status may be swept, the orders in other statuses
+// may still be inscribed or refunded by the runner.
+func sweepable(status tables.OrderStatus) bool {
+	switch status {
+	case tables.OrderStatusExpired, tables.OrderStatusFail, tables.OrderStatusSuccess:
+		return true
+	default:
+		return false
+	}
+}
+
+// SweepOrder spends the unspent payments of an expired, failed or succeeded order to an address by the taproot key path,
+// feeRate is in sat/kvB and 0 uses the refund fee rate. The status of the order is left as it is.
+func (b *Runner) SweepOrder(order *tables.InscribeOrder, payments []*tables.InscribeOrderPayment, address string, feeRate int64) (string, error) {
+	if !sweepable(order.Status) {
+		return "", fmt.Errorf("order status %d is not expired, failed or succeeded", order.Status)
+	}
+	if feeRate == 0 {
+		var err error
+		if feeRate, err = b.refundTxFeeRate(); err != nil {
+			return "", err
+		}
+	}
+	sweep := *order
+	sweep.RefundAddress = address
+	sweepTx, err := b.signRefundTx(&sweep, payments, feeRate)
+	if err != nil {
+		return "", err
+	}
+	txHash, err := b.client.SendRawTransaction(sweepTx, false)
+	if err != nil {
+		return "", err
+	}
+	return txHash.String(), nil
+}
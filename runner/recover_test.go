@@ -0,0 +1,72 @@
+package runner
+
+import (
+	"encoding/json"
+	"github.com/inscription-c/explorer-api/tables"
+	"testing"
+)
+
+func TestParseScanTxOutSet(t *testing.T) {
+	scripts := map[string]string{"5120aa": "order1", "5120bb": "order2"}
+	res := json.RawMessage(`{"success":true,"txouts":10,"height":100,"unspents":[
+		{"txid":"aa","vout":1,"scriptPubKey":"5120aa","desc":"rawtr(x)","amount":0.00010546,"height":99},
+		{"txid":"bb","vout":0,"scriptPubKey":"5120bb","desc":"rawtr(y)","amount":1.5,"height":100},
+		{"txid":"cc","vout":2,"scriptPubKey":"5120aa","desc":"rawtr(x)","amount":0.00000546,"height":100}
+	],"total_amount":1.50011092}`)
+	payments, err := parseScanTxOutSet(scripts, res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]tables.InscribeOrderPayment{
+		"order1": {
+			{OrderId: "order1", TxId: "aa", Index: 1, Value: 10546, Height: 99},
+			{OrderId: "order1", TxId: "cc", Index: 2, Value: 546, Height: 100},
+		},
+		"order2": {
+			{OrderId: "order2", TxId: "bb", Index: 0, Value: 150000000, Height: 100},
+		},
+	}
+	if len(payments) != len(want) {
+		t.Fatalf("got payments of %d orders, want %d", len(payments), len(want))
+	}
+	for orderId, list := range want {
+		if len(payments[orderId]) != len(list) {
+			t.Fatalf("got %d payments of %s, want %d", len(payments[orderId]), orderId, len(list))
+		}
+		for i, payment := range payments[orderId] {
+			if *payment != list[i] {
+				t.Fatalf("payment %d of %s = %+v, want %+v", i, orderId, *payment, list[i])
+			}
+		}
+	}
+
+	if _, err := parseScanTxOutSet(scripts, json.RawMessage(`{"success":false}`)); err == nil {
+		t.Fatal("parsed a failed scan")
+	}
+	unknown := json.RawMessage(`{"success":true,"unspents":[{"txid":"dd","vout":0,"scriptPubKey":"5120dd","amount":1,"height":1}]}`)
+	if _, err := parseScanTxOutSet(scripts, unknown); err == nil {
+		t.Fatal("parsed an output of an unknown script")
+	}
+}
+
+func TestSweepable(t *testing.T) {
+	tests := []struct {
+		status tables.OrderStatus
+		want   bool
+	}{
+		{tables.OrderStatusExpired, true},
+		{tables.OrderStatusFail, true},
+		{tables.OrderStatusSuccess, true},
+		{tables.OrderStatusDefault, false},
+		{tables.OrderStatusFeeNotEnough, false},
+		{tables.OrderStatusCommitSeen, false},
+		{tables.OrderStatusRevealSend, false},
+		{tables.OrderStatusRefunding, false},
+		{tables.OrderStatusRefunded, false},
+	}
+	for _, tt := range tests {
+		if got := sweepable(tt.status); got != tt.want {
+			t.Fatalf("sweepable(%d) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/inscription-c/cins/inscription"
+	"github.com/inscription-c/cins/pkg/signal"
+	"github.com/inscription-c/cins/pkg/util"
+	"github.com/inscription-c/cins/pkg/util/txscript"
+	"github.com/inscription-c/explorer-api/constants"
+	"github.com/inscription-c/explorer-api/dao"
+	"github.com/inscription-c/explorer-api/log"
+	"github.com/inscription-c/explorer-api/tables"
+	"math"
+	"time"
+)
+
+const (
+	// defaultOrderExpireAfter is how long an order waits for its payment by default.
+	defaultOrderExpireAfter = time.Hour * 24
+	// refundConfTarget is the confirmation target of the fee estimate of refund transactions.
+	refundConfTarget = 6
+	// minRelayFeeRate is the default min relay fee rate of the node in sat/kvB.
+	minRelayFeeRate = 1000
+	// expireBatchSize is how many orders are expired in a transaction.
+	expireBatchSize = 500
+)
+
+var errRefundValueTooLow = errors.New("refund value is below the dust limit")
+
+// RefundOrders expires the orders that are not fully paid in time, and refunds the payments of
+// expired orders to their refund address.
+func (b *Runner) RefundOrders() {
+	b.Go(func() error {
+		ticker := time.NewTicker(time.Second * 30)
+		defer ticker.Stop()
+		for range ticker.C {
+			select {
+			case <-signal.InterruptChannel:
+				return nil
+			default:
+				if err := b.expireOrders(); err != nil {
+					log.Log.Errorf("expireOrders err: %s", err)
+				}
+				if err := b.refundOrders(); err != nil {
+					log.Log.Errorf("refundOrders err: %s", err)
+				}
+				if err := b.confirmRefunds(); err != nil {
+					log.Log.Errorf("confirmRefunds err: %s", err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// expireOrders expires the orders that are not paid in time, a batch of orders is expired in a transaction.
+func (b *Runner) expireOrders() error {
+	before := time.Now().Add(-b.orderExpireAfter)
+	n, afterId := 0, uint64(0)
+	for {
+		orders, err := b.db.FindExpirableInscribeOrders(before, afterId, expireBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(orders) == 0 {
+			break
+		}
+		afterId = orders[len(orders)-1].Id
+
+		if err := b.db.Transaction(func(wtx *dao.DB) error {
+			for _, order := range orders {
+				expired, err := wtx.ExpireInscribeOrder(order)
+				if err != nil {
+					return err
+				}
+				if !expired {
+					continue
+				}
+				if err := wtx.CreateInscribeOrderEvent(expiredEvent(order)); err != nil {
+					return err
+				}
+				n++
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	if n > 0 {
+		log.Log.Infof("Expired %d inscribe orders", n)
+	}
+	return nil
+}
+
+// expiredEvent return the event of an expired order.
+func expiredEvent(order *tables.InscribeOrder) *tables.InscribeOrderEvent {
+	event := &tables.InscribeOrderEvent{
+		OrderId: order.OrderId,
+		Type:    tables.OrderEventExpired,
+		Status:  tables.OrderStatusExpired,
+	}
+	if order.CommitTxId != "" {
+		if order.RefundAddress != "" {
+			event.Message = "order is expired, the payments are refunded"
+		} else {
+			event.Message = "order is expired and has no refund address, the payments need manual handling"
+		}
+	}
+	return event
+}
+
+func (b *Runner) refundOrders() error {
+	orders, err := b.db.FindRefundableInscribeOrders()
+	if err != nil {
+		return err
+	}
+	if len(orders) == 0 {
+		return nil
+	}
+	feeRate, err := b.refundTxFeeRate()
+	if err != nil {
+		return err
+	}
+
+	for _, order := range orders {
+		payments, err := b.unspentPayments(order)
+		if err != nil {
+			return err
+		}
+		if len(payments) == 0 {
+			log.Log.Warn("RefundPaymentsSpent", order.OrderId)
+			continue
+		}
+
+		refundTx, err := b.signRefundTx(order, payments, feeRate)
+		if errors.Is(err, errRefundValueTooLow) {
+			log.Log.Warn("RefundValueTooLow", order.OrderId, len(payments), feeRate)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := b.client.SendRawTransaction(refundTx, false); err != nil {
+			log.Log.Error("RefundTxSendError", err, order.OrderId)
+			continue
+		}
+
+		order.Status = tables.OrderStatusRefunding
+		order.RefundTxId = refundTx.TxHash().String()
+		if err := b.db.SaveInscribeOrder(order); err != nil {
+			return err
+		}
+		if err := b.db.CreateInscribeOrderEvent(&tables.InscribeOrderEvent{
+			OrderId: order.OrderId,
+			Type:    tables.OrderEventRefundSent,
+			Status:  order.Status,
+			TxId:    order.RefundTxId,
+			Amount:  refundTx.TxOut[0].Value,
+		}); err != nil {
+			return err
+		}
+		log.Log.Infof("RefundTxSendSuccess %s %s", order.OrderId, order.RefundTxId)
+	}
+	return nil
+}
+
+// unspentPayments return the payments of an order that are not spent yet.
+func (b *Runner) unspentPayments(order *tables.InscribeOrder) ([]*tables.InscribeOrderPayment, error) {
+	payments, err := b.db.FindInscribeOrderPayments(order.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	unspent := make([]*tables.InscribeOrderPayment, 0, len(payments))
+	for _, payment := range payments {
+		txHash, err := chainhash.NewHashFromStr(payment.TxId)
+		if err != nil {
+			return nil, err
+		}
+		txOut, err := b.client.GetTxOut(txHash, payment.Index, true)
+		if err != nil {
+			return nil, err
+		}
+		if txOut == nil {
+			continue
+		}
+		unspent = append(unspent, payment)
+	}
+	return unspent, nil
+}
+
+// confirmRefunds marks the refunding orders as refunded once the refund transaction is confirmed.
+func (b *Runner) confirmRefunds() error {
+	orders, err := b.db.FindInscribeOrdersByStatus(tables.OrderStatusRefunding)
+	if err != nil {
+		return err
+	}
+	for _, order := range orders {
+		refundTxHash, err := chainhash.NewHashFromStr(order.RefundTxId)
+		if err != nil {
+			return err
+		}
+		tx, err := b.client.GetRawTransactionVerbose(refundTxHash)
+		var jsonErr *btcjson.RPCError
+		if errors.As(err, &jsonErr) && jsonErr.Code == btcjson.ErrRPCNoTxInfo {
+			log.Log.Warn("RefundTxNotFound", order.OrderId, order.RefundTxId)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if tx.Confirmations == 0 {
+			continue
+		}
+
+		// the payments that arrived while refunding are refunded in the next round
+		payments, err := b.unspentPayments(order)
+		if err != nil {
+			return err
+		}
+		order.Status = tables.OrderStatusRefunded
+		if len(payments) > 0 {
+			order.Status = tables.OrderStatusExpired
+		}
+		if err := b.db.SaveInscribeOrder(order); err != nil {
+			return err
+		}
+		if err := b.db.CreateInscribeOrderEvent(&tables.InscribeOrderEvent{
+			OrderId: order.OrderId,
+			Type:    tables.OrderEventRefunded,
+			Status:  order.Status,
+			TxId:    order.RefundTxId,
+		}); err != nil {
+			return err
+		}
+		log.Log.Infof("Refunded order: %s refundTxId: %s unrefunded payments: %d", order.OrderId, order.RefundTxId, len(payments))
+	}
+	return nil
+}
+
+// refundTxFeeRate return the configured refund fee rate or the fee estimate of the node in sat/kvB.
+func (b *Runner) refundTxFeeRate() (int64, error) {
+	if b.refundFeeRate > 0 {
+		return b.refundFeeRate, nil
+	}
+	resp, err := b.client.EstimateSmartFee(refundConfTarget, &btcjson.EstimateModeConservative)
+	if err != nil {
+		return 0, err
+	}
+	if resp.FeeRate == nil {
+		return 0, errors.New("fee estimate is not available")
+	}
+	feeRate := int64(math.Ceil(*resp.FeeRate * float64(constants.OneBtc)))
+	if feeRate < minRelayFeeRate {
+		feeRate = minRelayFeeRate
+	}
+	return feeRate, nil
+}
+
+// signRefundTx spends the payments of an order to its refund address by the taproot key path,
+// so that the inscription is not revealed. The key is tweaked by the root of the reveal script.
+func (b *Runner) signRefundTx(order *tables.InscribeOrder, payments []*tables.InscribeOrderPayment, feeRate int64) (*wire.MsgTx, error) {
+	revealTxData, err := hex.DecodeString(order.RevealTxRaw)
+	if err != nil {
+		return nil, err
+	}
+	revealTx := &wire.MsgTx{}
+	if err := revealTx.Deserialize(bytes.NewReader(revealTxData)); err != nil {
+		return nil, err
+	}
+	revealScript := revealTx.TxIn[0].Witness[1]
+
+	priKey, err := b.orderPriKey(order)
+	if err != nil {
+		return nil, err
+	}
+	commitPkScript, err := util.AddressScript(order.RevealAddress, util.ActiveNet.Params)
+	if err != nil {
+		return nil, err
+	}
+	refundPkScript, err := util.AddressScript(order.RefundAddress, util.ActiveNet.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	refundTx := wire.NewMsgTx(2)
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut, len(payments))
+	inputValue := int64(0)
+	for _, payment := range payments {
+		txHash, err := chainhash.NewHashFromStr(payment.TxId)
+		if err != nil {
+			return nil, err
+		}
+		outPoint := wire.NewOutPoint(txHash, payment.Index)
+		refundTx.AddTxIn(wire.NewTxIn(outPoint, nil, wire.TxWitness{make([]byte, 64)}))
+		prevOuts[*outPoint] = wire.NewTxOut(payment.Value, commitPkScript)
+		inputValue += payment.Value
+	}
+	refundTx.AddTxOut(wire.NewTxOut(0, refundPkScript))
+	value := inputValue - inscription.CalculateTxFee(refundTx, feeRate)
+	if value < dustLimit(refundPkScript) {
+		return nil, errRefundValueTooLow
+	}
+	refundTx.TxOut[0].Value = value
+
+	sigHashes := txscript.NewTxSigHashes(refundTx, txscript.NewMultiPrevOutFetcher(prevOuts))
+	tapScriptRoot := txscript.NewBaseTapLeaf(revealScript).TapHash()
+	for i, payment := range payments {
+		sig, err := txscript.RawTxInTaprootSignature(refundTx, sigHashes, i, payment.Value,
+			commitPkScript, tapScriptRoot[:], txscript.SigHashDefault, priKey)
+		if err != nil {
+			return nil, err
+		}
+		refundTx.TxIn[i].Witness = wire.TxWitness{sig}
+	}
+	return refundTx, nil
+}
+
+// orderPriKey return the private key of the reveal address of an order, it's derived from the HD key by the path
+// of the order, or decrypted by the keyring.
+func (b *Runner) orderPriKey(order *tables.InscribeOrder) (*btcec.PrivateKey, error) {
+	if order.RevealKeyPath != "" {
+		return b.hdKey.Derive(order.RevealKeyPath)
+	}
+	priKeyBytes, err := b.keyring.Decrypt(order.RevealPriKey)
+	if err != nil {
+		return nil, err
+	}
+	priKey, _ := btcec.PrivKeyFromBytes(priKeyBytes)
+	return priKey, nil
+}
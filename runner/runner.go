@@ -5,8 +5,9 @@ import (
 	"context"
 	"encoding/hex"
 	"errors"
-	"github.com/btcsuite/btcd/btcec/v2"
+	"fmt"
 	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/inscription-c/cins/btcd/rpcclient"
 	"github.com/inscription-c/cins/pkg/signal"
@@ -14,7 +15,9 @@ import (
 	"github.com/inscription-c/cins/pkg/util/txscript"
 	"github.com/inscription-c/explorer-api/dao"
 	"github.com/inscription-c/explorer-api/dao/indexer"
+	"github.com/inscription-c/explorer-api/keystore"
 	"github.com/inscription-c/explorer-api/log"
+	"github.com/inscription-c/explorer-api/stream"
 	"github.com/inscription-c/explorer-api/tables"
 	"golang.org/x/sync/errgroup"
 	"gorm.io/gorm"
@@ -23,10 +26,20 @@ import (
 )
 
 type Opts struct {
-	client    *rpcclient.Client
-	height    uint32
-	db        *dao.DB
-	indexerDB *indexer.DB
+	client             *rpcclient.Client
+	height             uint32
+	db                 *dao.DB
+	indexerDB          *indexer.DB
+	orderExpireAfter   time.Duration
+	refundFeeRate      int64
+	bumpAfter          time.Duration
+	maxFeeRate         int64
+	minPostage         int64
+	mempoolReveal      bool
+	webhookMaxAttempts int
+	stream             *stream.Hub
+	keyring            *keystore.Keyring
+	hdKey              *keystore.HDKey
 }
 
 type OpFunc func(*Opts)
@@ -55,6 +68,77 @@ func WithIndexerDB(db *indexer.DB) OpFunc {
 	}
 }
 
+// WithOrderExpireAfter sets how long an order waits for its payment, 0 uses defaultOrderExpireAfter.
+func WithOrderExpireAfter(d time.Duration) OpFunc {
+	return func(opts *Opts) {
+		opts.orderExpireAfter = d
+	}
+}
+
+// WithRefundFeeRate sets the fee rate of refund transactions in sat/kvB, 0 uses the fee estimate of the node.
+func WithRefundFeeRate(feeRate int64) OpFunc {
+	return func(opts *Opts) {
+		opts.refundFeeRate = feeRate
+	}
+}
+
+// WithBumpAfter sets how long a reveal tx stays unconfirmed before it's replaced, 0 uses defaultBumpAfter.
+func WithBumpAfter(d time.Duration) OpFunc {
+	return func(opts *Opts) {
+		opts.bumpAfter = d
+	}
+}
+
+// WithMaxFeeRate sets the max fee rate of replacements in sat/kvB, 0 is unlimited.
+func WithMaxFeeRate(feeRate int64) OpFunc {
+	return func(opts *Opts) {
+		opts.maxFeeRate = feeRate
+	}
+}
+
+// WithMinPostage sets the value an inscription output can be reduced to when it pays the fee of a replacement,
+// 0 uses defaultMinPostage.
+func WithMinPostage(postage int64) OpFunc {
+	return func(opts *Opts) {
+		opts.minPostage = postage
+	}
+}
+
+// WithMempoolReveal sets whether the reveal tx is sent as soon as its commit tx is in the mempool.
+func WithMempoolReveal(enable bool) OpFunc {
+	return func(opts *Opts) {
+		opts.mempoolReveal = enable
+	}
+}
+
+// WithWebhookMaxAttempts sets how many times a callback is attempted before it fails, 0 uses defaultWebhookMaxAttempts.
+func WithWebhookMaxAttempts(attempts int) OpFunc {
+	return func(opts *Opts) {
+		opts.webhookMaxAttempts = attempts
+	}
+}
+
+// WithStream sets the hub the new blocks, inscriptions and order status changes are published to.
+func WithStream(hub *stream.Hub) OpFunc {
+	return func(opts *Opts) {
+		opts.stream = hub
+	}
+}
+
+// WithKeyring sets the keyring the reveal private keys and callback secrets of the orders are decrypted by.
+func WithKeyring(keyring *keystore.Keyring) OpFunc {
+	return func(opts *Opts) {
+		opts.keyring = keyring
+	}
+}
+
+// WithHDKey sets the HD key the reveal private keys of the orders with a derivation path are derived from.
+func WithHDKey(hdKey *keystore.HDKey) OpFunc {
+	return func(opts *Opts) {
+		opts.hdKey = hdKey
+	}
+}
+
 type Runner struct {
 	Opts
 	errgroup.Group
@@ -66,6 +150,18 @@ func NewRunner(opts ...OpFunc) *Runner {
 		opt(ops)
 	}
 
+	if ops.orderExpireAfter == 0 {
+		ops.orderExpireAfter = defaultOrderExpireAfter
+	}
+	if ops.bumpAfter == 0 {
+		ops.bumpAfter = defaultBumpAfter
+	}
+	if ops.minPostage == 0 {
+		ops.minPostage = defaultMinPostage
+	}
+	if ops.webhookMaxAttempts == 0 {
+		ops.webhookMaxAttempts = defaultWebhookMaxAttempts
+	}
 	return &Runner{
 		Opts: *ops,
 	}
@@ -74,6 +170,14 @@ func NewRunner(opts ...OpFunc) *Runner {
 func (b *Runner) Start() {
 	b.BlockParser()
 	b.UpdateRevealTx()
+	b.CBRC20Parser()
+	b.ParentParser()
+	b.RarityParser()
+	b.RefundOrders()
+	b.BumpRevealTxs()
+	b.WatchMempool()
+	b.Webhooks()
+	b.Stream()
 }
 
 func (b *Runner) BlockParser() {
@@ -135,21 +239,40 @@ func (b *Runner) processRevealTx() error {
 		if err := b.db.ScanRows(rows, &order); err != nil {
 			return err
 		}
-		inscriptionId := &tables.InscriptionId{
-			TxId:   order.RevealTxId,
-			Offset: 0,
-		}
-		inscription, err := b.indexerDB.GetInscriptionById(inscriptionId)
-		if err != nil {
-			return err
+		// a replaced reveal tx may be confirmed before its replacement
+		var inscription tables.Inscriptions
+		for _, revealTxId := range order.RevealTxIds() {
+			inscription, err = b.indexerDB.GetInscriptionById(tables.NewInscriptionId(revealTxId, 0))
+			if err != nil {
+				return err
+			}
+			if inscription.Id > 0 {
+				break
+			}
 		}
 		if inscription.Id == 0 {
 			continue
 		}
+		inscriptionId := &inscription.InscriptionId
 
 		order.Status = tables.OrderStatusSuccess
+		order.RevealTxId = inscriptionId.TxId
 		order.InscriptionId = *inscriptionId
-		if err := b.db.Save(&order).Error; err != nil {
+		if err := b.db.Transaction(func(wtx *dao.DB) error {
+			if err := wtx.SaveInscribeOrder(&order); err != nil {
+				return err
+			}
+			if err := wtx.CreateInscribeOrderEvent(&tables.InscribeOrderEvent{
+				OrderId: order.OrderId,
+				Type:    tables.OrderEventInscribed,
+				Status:  order.Status,
+				Height:  inscription.Height,
+				TxId:    order.RevealTxId,
+			}); err != nil {
+				return err
+			}
+			return wtx.UpdateInscribeOrderItemsInscriptionId(order.OrderId, order.RevealTxId)
+		}); err != nil {
 			return err
 		}
 		log.Log.Infof("Inscribe Success, order: %s inscriptionId: %s", order.OrderId, inscriptionId)
@@ -209,19 +332,78 @@ func (b *Runner) indexBlock() error {
 						if err != nil {
 							return err
 						}
-						order, err := b.db.GetInscribeOrdersByRevealAddress(revealTxAddress.String())
+						order, err := wtx.GetInscribeOrdersByRevealAddress(revealTxAddress.String())
 						if err != nil {
 							return err
 						}
-						if order.Id == 0 || order.Status != tables.OrderStatusDefault {
+						if order.Id == 0 {
 							continue
 						}
 
-						if txOut.Value < order.RevealTxValue {
-							log.Log.Warn("RevealTxValue is not enough", order.OrderId, tx.TxHash().String(), txOut.Value, order.RevealTxValue)
+						if err := wtx.CreateInscribeOrderPayment(&tables.InscribeOrderPayment{
+							OrderId: order.OrderId,
+							TxId:    tx.TxHash().String(),
+							Index:   uint32(idx),
+							Value:   txOut.Value,
+							Height:  b.height,
+						}); err != nil {
+							return err
+						}
+						paymentEvent := &tables.InscribeOrderEvent{
+							OrderId: order.OrderId,
+							Type:    tables.OrderEventPayment,
+							Status:  order.Status,
+							Height:  b.height,
+							TxId:    tx.TxHash().String(),
+							Amount:  txOut.Value,
+						}
+						if refundsPayments(order.Status) {
+							if order.RefundAddress != "" {
+								paymentEvent.Message = "order is expired, the payment is refunded"
+							} else {
+								paymentEvent.Message = "order is expired and has no refund address, the payment needs manual handling"
+							}
+						}
+						if err := wtx.CreateInscribeOrderBlockEvent(paymentEvent); err != nil {
+							return err
+						}
+						payments, err := wtx.FindInscribeOrderPayments(order.OrderId)
+						if err != nil {
+							return err
+						}
+						paid := int64(0)
+						for _, payment := range payments {
+							paid += payment.Value
+						}
+						// the confirmed commit tx may differ from the one seen in the mempool
+						if len(payments) == 1 {
+							order.CommitTxId = tx.TxHash().String()
+							order.CommitTxIndex = uint32(idx)
+							order.CommitTxValue = txOut.Value
+						}
+
+						if refundsPayments(order.Status) {
+							log.Log.Warn("CommitTxAfterExpired", order.OrderId, tx.TxHash().String(), txOut.Value, order.Status)
+							// a refunded order is refunded again, a refunding one once its refund tx is confirmed
+							if order.Status == tables.OrderStatusRefunded {
+								order.Status = tables.OrderStatusExpired
+							}
+						} else if required := order.RequiredValue(len(payments)); paid < required {
+							log.Log.Warn("RevealTxValue is not enough", order.OrderId, tx.TxHash().String(), paid, required)
 							order.Status = tables.OrderStatusFeeNotEnough
+							if err := wtx.CreateInscribeOrderBlockEvent(&tables.InscribeOrderEvent{
+								OrderId: order.OrderId,
+								Type:    tables.OrderEventFeeNotEnough,
+								Status:  order.Status,
+								Height:  b.height,
+								TxId:    tx.TxHash().String(),
+								Amount:  paid,
+								Message: fmt.Sprintf("paid %d of %d", paid, required),
+							}); err != nil {
+								return err
+							}
 						} else {
-							revealTx, err := b.signRevealTx(tx, &order, idx)
+							revealTx, err := b.signRevealTx(&order, payments)
 							if err != nil {
 								return err
 							}
@@ -235,13 +417,41 @@ func (b *Runner) indexBlock() error {
 								log.Log.Warn("InscriptionIdExists", order.OrderId, inscription.Id)
 								order.Status = tables.OrderStatusSuccess
 								order.InscriptionId = inscription.InscriptionId
+								if err := wtx.UpdateInscribeOrderBlockItemsInscriptionId(b.height, order.OrderId, revealTxId); err != nil {
+									return err
+								}
+								if err := wtx.CreateInscribeOrderBlockEvent(&tables.InscribeOrderEvent{
+									OrderId: order.OrderId,
+									Type:    tables.OrderEventInscribed,
+									Status:  order.Status,
+									Height:  b.height,
+									TxId:    revealTxId,
+								}); err != nil {
+									return err
+								}
+							} else if revealTxId == order.RevealTxId {
+								log.Log.Infof("RevealTxSentInMempool %s %s", order.OrderId, revealTxId)
+								order.Status = tables.OrderStatusRevealSend
+								order.RevealSentAt = time.Now()
 							} else {
 								if _, err := b.client.SendRawTransaction(revealTx, false); err != nil {
 									log.Log.Error("RevealTxSendError", err, order.OrderId, order.Status)
+									b.recordRevealFailed(&order, revealTxId, err)
 									return err
 								}
 								log.Log.Infof("RevealTxSendSuccess %s %s %d", order.OrderId, revealTxId, order.Status)
 								order.Status = tables.OrderStatusRevealSend
+								order.RevealSentAt = time.Now()
+								if err := wtx.CreateInscribeOrderBlockEvent(&tables.InscribeOrderEvent{
+									OrderId: order.OrderId,
+									Type:    tables.OrderEventRevealSent,
+									Status:  order.Status,
+									Height:  b.height,
+									TxId:    revealTxId,
+									Amount:  paid,
+								}); err != nil {
+									return err
+								}
 							}
 
 							revealTxBuf := bytes.NewBufferString("")
@@ -255,7 +465,6 @@ func (b *Runner) indexBlock() error {
 							return err
 						}
 						log.Log.Info("RevealTx", order.OrderId, order.RevealTxId, order.Status)
-						break
 					}
 				}
 
@@ -287,10 +496,10 @@ func (b *Runner) indexBlock() error {
 }
 
 // signRevealTx is a method of the Inscription struct. It is responsible
-// for signing the reveal transaction of the Inscription. It sets the previous
-// outpoint of the reveal transaction input, calculates the signature hash, and
-// signs the reveal transaction input. It returns an error if there is an error in any of the steps.
-func (b *Runner) signRevealTx(commitTx *wire.MsgTx, order *tables.InscribeOrder, idx int) (*wire.MsgTx, error) {
+// for signing the reveal transaction of the Inscription. The first payment is spent by the
+// reveal script path and reveals the inscription, the other payments of a topped up order
+// are spent by the key path. It returns an error if there is an error in any of the steps.
+func (b *Runner) signRevealTx(order *tables.InscribeOrder, payments []*tables.InscribeOrderPayment) (*wire.MsgTx, error) {
 	revealTxData, err := hex.DecodeString(order.RevealTxRaw)
 	if err != nil {
 		return nil, err
@@ -299,45 +508,115 @@ func (b *Runner) signRevealTx(commitTx *wire.MsgTx, order *tables.InscribeOrder,
 	if err := revealTx.Deserialize(bytes.NewReader(revealTxData)); err != nil {
 		return nil, err
 	}
-	commitTxHash := commitTx.TxHash()
-	revealTx.TxIn[0].PreviousOutPoint = *wire.NewOutPoint(&commitTxHash, uint32(idx))
-	revealTx.TxIn[0].SignatureScript = nil
+
+	inputValue := int64(0)
+	for i, payment := range payments {
+		commitTxHash, err := chainhash.NewHashFromStr(payment.TxId)
+		if err != nil {
+			return nil, err
+		}
+		outPoint := wire.NewOutPoint(commitTxHash, payment.Index)
+		if i == 0 {
+			revealTx.TxIn[0].PreviousOutPoint = *outPoint
+			revealTx.TxIn[0].SignatureScript = nil
+		} else {
+			revealTx.AddTxIn(wire.NewTxIn(outPoint, nil, wire.TxWitness{make([]byte, 64)}))
+		}
+		inputValue += payment.Value
+	}
+
+	// The overpayment goes back to the user by a change output, it must be added before signing.
+	if err := addChangeOutput(revealTx, order, inputValue); err != nil {
+		return nil, err
+	}
+	if err := b.signRevealInputs(revealTx, order, payments); err != nil {
+		return nil, err
+	}
+	return revealTx, nil
+}
+
+// signRevealInputs signs the inputs of a reveal transaction, the inputs spend the payments in the same order.
+// It is used again when the outputs of a sent reveal transaction change.
+func (b *Runner) signRevealInputs(revealTx *wire.MsgTx, order *tables.InscribeOrder, payments []*tables.InscribeOrderPayment) error {
+	commitPkScript, err := util.AddressScript(order.RevealAddress, util.ActiveNet.Params)
+	if err != nil {
+		return err
+	}
 
 	// It creates a new MultiPrevOutFetcher to fetch previous outputs.
-	prevFetcher := txscript.NewMultiPrevOutFetcher(map[wire.OutPoint]*wire.TxOut{
-		revealTx.TxIn[idx].PreviousOutPoint: {
-			Value:    commitTx.TxOut[idx].Value,
-			PkScript: commitTx.TxOut[idx].PkScript,
-		},
-	})
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut, len(payments))
+	for i, payment := range payments {
+		prevOuts[revealTx.TxIn[i].PreviousOutPoint] = wire.NewTxOut(payment.Value, commitPkScript)
+	}
+	prevFetcher := txscript.NewMultiPrevOutFetcher(prevOuts)
 
 	// It creates new transaction signature hashes using the reveal transaction and the MultiPrevOutFetcher.
 	sigHashes := txscript.NewTxSigHashes(revealTx, prevFetcher)
 
 	// It calculates the signature hash for the reveal transaction.
-	signHash, err := txscript.CalcTapScriptSignatureHash(sigHashes, txscript.SigHashDefault, revealTx, 0, prevFetcher, txscript.NewBaseTapLeaf(revealTx.TxIn[0].Witness[1]))
+	revealScript := revealTx.TxIn[0].Witness[1]
+	signHash, err := txscript.CalcTapScriptSignatureHash(sigHashes, txscript.SigHashDefault, revealTx, 0, prevFetcher, txscript.NewBaseTapLeaf(revealScript))
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// It signs the signature hash using the private key.
-	revealTxPriKeyBytes, err := hex.DecodeString(order.RevealPriKey)
-	if err != nil {
-		return nil, err
-	}
-	priKey, _ := btcec.PrivKeyFromBytes(revealTxPriKeyBytes)
+	priKey, err := b.orderPriKey(order)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	signature, err := schnorr.Sign(priKey, signHash)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// It serializes the signature and sets it as the witness of the reveal transaction input.
 	sig := signature.Serialize()
 	revealTx.TxIn[0].Witness[0] = sig
-	return revealTx, nil
+
+	// The other inputs are signed by the key tweaked with the root of the reveal script.
+	tapScriptRoot := txscript.NewBaseTapLeaf(revealScript).TapHash()
+	for i := 1; i < len(revealTx.TxIn); i++ {
+		sig, err := txscript.RawTxInTaprootSignature(revealTx, sigHashes, i, payments[i].Value,
+			commitPkScript, tapScriptRoot[:], txscript.SigHashDefault, priKey)
+		if err != nil {
+			return err
+		}
+		revealTx.TxIn[i].Witness = wire.TxWitness{sig}
+	}
+	return nil
+}
+
+// recordRevealFailed records why the reveal tx of an order can't be sent. The block is parsed again,
+// so the event is written outside the block transaction and only when the error changes.
+func (b *Runner) recordRevealFailed(order *tables.InscribeOrder, revealTxId string, sendErr error) {
+	last, err := b.db.LastInscribeOrderEvent(order.OrderId)
+	if err != nil {
+		log.Log.Error("LastInscribeOrderEvent", err, order.OrderId)
+		return
+	}
+	if last.Type == tables.OrderEventRevealFailed && last.TxId == revealTxId && last.Message == sendErr.Error() {
+		return
+	}
+	if err := b.db.CreateInscribeOrderEvent(&tables.InscribeOrderEvent{
+		OrderId: order.OrderId,
+		Type:    tables.OrderEventRevealFailed,
+		Status:  order.Status,
+		Height:  b.height,
+		TxId:    revealTxId,
+		Message: eventMessage(sendErr),
+	}); err != nil {
+		log.Log.Error("CreateInscribeOrderEvent", err, order.OrderId)
+	}
+}
+
+// eventMessage return an error message that fits the message column of an order event.
+func eventMessage(err error) string {
+	msg := err.Error()
+	if len(msg) > 1024 {
+		msg = msg[:1024]
+	}
+	return msg
 }
 
 // fetchBlockFrom is a method that fetches blocks from the blockchain, starting from a specified start height and ending at a specified end height.
@@ -469,3 +748,9 @@ func (b *Runner) getBlockWithRetries(height uint32) (*wire.MsgBlock, error) {
 		}
 	}
 }
+
+// refundsPayments reports whether the payments of an order in a status are refunded instead of inscribed.
+func refundsPayments(status tables.OrderStatus) bool {
+	return status == tables.OrderStatusExpired || status == tables.OrderStatusRefunding ||
+		status == tables.OrderStatusRefunded
+}
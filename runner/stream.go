@@ -0,0 +1,240 @@
+package runner
+
+import (
+	"github.com/inscription-c/cins/pkg/signal"
+	"github.com/inscription-c/explorer-api/log"
+	"github.com/inscription-c/explorer-api/stream"
+	"github.com/inscription-c/explorer-api/tables"
+	"time"
+)
+
+const (
+	// streamBatchSize is how many blocks and inscriptions are published in a round at most, and the page size of the orders.
+	streamBatchSize = 500
+)
+
+type StreamBlock struct {
+	Height       uint32 `json:"height"`
+	Hash         string `json:"hash"`
+	Timestamp    int64  `json:"timestamp"`
+	Inscriptions int64  `json:"inscriptions"`
+}
+
+type StreamInscription struct {
+	InscriptionId     string `json:"inscription_id"`
+	InscriptionNumber int64  `json:"inscription_number"`
+	ContentType       string `json:"content_type"`
+	Owner             string `json:"owner"`
+	Height            uint32 `json:"height"`
+	Ticker            string `json:"ticker,omitempty"`
+}
+
+type StreamOrder struct {
+	OrderId        string             `json:"order_id"`
+	Status         tables.OrderStatus `json:"status"`
+	ReceiveAddress string             `json:"receive_address"`
+	InscriptionId  string             `json:"inscription_id,omitempty"`
+	CommitTxId     string             `json:"commit_tx_id,omitempty"`
+	RevealTxId     string             `json:"reveal_tx_id,omitempty"`
+}
+
+// streamCursor is where the stream publisher stopped. An order is updated without a status change too,
+// orders keeps the last published status of the orders so that only the status changes are published.
+type streamCursor struct {
+	height      uint32
+	sequenceNum int64
+	updatedAt   time.Time
+	orders      map[string]*streamOrderState
+}
+
+type streamOrderState struct {
+	status    tables.OrderStatus
+	updatedAt time.Time
+}
+
+// Stream publishes the new blocks, the new inscriptions and the status changes of orders to the stream hub.
+// It starts from the current state, nothing before is published.
+func (b *Runner) Stream() {
+	if b.stream == nil {
+		return
+	}
+	b.Go(func() error {
+		cursor := &streamCursor{orders: make(map[string]*streamOrderState)}
+		ticker := time.NewTicker(time.Second * 2)
+		defer ticker.Stop()
+		for range ticker.C {
+			select {
+			case <-signal.InterruptChannel:
+				return nil
+			default:
+				if cursor.updatedAt.IsZero() {
+					if err := b.initStreamCursor(cursor); err != nil {
+						log.Log.Errorf("initStreamCursor err: %s", err)
+					}
+					continue
+				}
+				if err := b.publishBlocks(cursor); err != nil {
+					log.Log.Errorf("publishBlocks err: %s", err)
+				}
+				if err := b.publishInscriptions(cursor); err != nil {
+					log.Log.Errorf("publishInscriptions err: %s", err)
+				}
+				if err := b.publishOrders(cursor); err != nil {
+					log.Log.Errorf("publishOrders err: %s", err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (b *Runner) initStreamCursor(cursor *streamCursor) error {
+	block, err := b.indexerDB.LatestBlockInfo()
+	if err != nil {
+		return err
+	}
+	order, err := b.db.LatestInscribeOrder()
+	if err != nil {
+		return err
+	}
+	cursor.height = block.Height
+	cursor.sequenceNum = block.SequenceNum
+	cursor.updatedAt = order.UpdatedAt
+	if order.Id == 0 {
+		cursor.updatedAt = time.Unix(0, 0)
+	}
+	return nil
+}
+
+func (b *Runner) publishBlocks(cursor *streamCursor) error {
+	// the indexer rolls back the blocks of a reorg, the replacing blocks are published again
+	height, err := b.indexerDB.BlockHeight()
+	if err != nil {
+		return err
+	}
+	if height < cursor.height {
+		cursor.height = height
+	}
+
+	prev, err := b.indexerDB.GetBlockInfoByHeight(cursor.height)
+	if err != nil {
+		return err
+	}
+	blocks, err := b.indexerDB.FindBlockInfosAfter(cursor.height, streamBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		header, err := block.LoadHeader()
+		if err != nil {
+			return err
+		}
+		b.stream.Publish(stream.NewBlockEvent(&StreamBlock{
+			Height:       block.Height,
+			Hash:         header.BlockHash().String(),
+			Timestamp:    block.Timestamp,
+			Inscriptions: block.SequenceNum - prev.SequenceNum,
+		}))
+		cursor.height = block.Height
+		prev = *block
+	}
+	return nil
+}
+
+func (b *Runner) publishInscriptions(cursor *streamCursor) error {
+	next, err := b.indexerDB.NextSequenceNumber()
+	if err != nil {
+		return err
+	}
+	if next-1 < cursor.sequenceNum {
+		cursor.sequenceNum = next - 1
+	}
+
+	list, err := b.indexerDB.FindInscriptionsAfterSequence(cursor.sequenceNum, streamBatchSize)
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	protocols, err := b.indexerDB.FindCBRC20ProtocolsInRange(list[0].SequenceNum, list[len(list)-1].SequenceNum)
+	if err != nil {
+		return err
+	}
+	tickers := make(map[int64]string, len(protocols))
+	for _, protocol := range protocols {
+		tickers[protocol.SequenceNum] = protocol.Ticker
+	}
+
+	for _, ins := range list {
+		ticker := tickers[ins.SequenceNum]
+		b.stream.Publish(stream.NewInscriptionEvent(&StreamInscription{
+			InscriptionId:     ins.InscriptionId.String(),
+			InscriptionNumber: ins.InscriptionNum,
+			ContentType:       ins.ContentType,
+			Owner:             ins.Owner,
+			Height:            ins.Height,
+			Ticker:            ticker,
+		}, ins.Owner, ticker))
+		cursor.sequenceNum = ins.SequenceNum
+	}
+	return nil
+}
+
+// publishOrders publishes the status changes of the orders updated since the cursor. updated_at is in seconds,
+// so the orders of the second of the cursor are read again in every round, and the orders are paged by
+// (updated_at, id) until the last page so that the cursor moves on when many orders share a second.
+func (b *Runner) publishOrders(cursor *streamCursor) error {
+	since, afterId := cursor.updatedAt, uint64(0)
+	for {
+		orders, err := b.db.FindInscribeOrdersUpdatedSince(since, afterId, streamBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, order := range orders {
+			b.publishOrder(cursor, order)
+			since, afterId = order.UpdatedAt, order.Id
+		}
+		if len(orders) < streamBatchSize {
+			break
+		}
+	}
+	if since.After(cursor.updatedAt) {
+		cursor.updatedAt = since
+	}
+
+	// the orders in a final status are forgotten once the cursor passed them, the others after a day
+	for orderId, state := range cursor.orders {
+		if (state.updatedAt.Before(cursor.updatedAt) && finalOrderStatus(state.status)) ||
+			state.updatedAt.Before(cursor.updatedAt.Add(-time.Hour*24)) {
+			delete(cursor.orders, orderId)
+		}
+	}
+	return nil
+}
+
+// publishOrder publishes an order if its status changed since it was published last.
+func (b *Runner) publishOrder(cursor *streamCursor, order *tables.InscribeOrder) {
+	state, ok := cursor.orders[order.OrderId]
+	if ok && state.status == order.Status {
+		state.updatedAt = order.UpdatedAt
+		return
+	}
+	cursor.orders[order.OrderId] = &streamOrderState{status: order.Status, updatedAt: order.UpdatedAt}
+
+	data := &StreamOrder{
+		OrderId:        order.OrderId,
+		Status:         order.Status,
+		ReceiveAddress: order.ReceiveAddress,
+		CommitTxId:     order.CommitTxId,
+		RevealTxId:     order.RevealTxId,
+	}
+	if order.InscriptionId.TxId != "" {
+		data.InscriptionId = order.InscriptionId.String()
+	}
+	b.stream.Publish(stream.NewOrderEvent(data, order.OrderId, order.ReceiveAddress))
+}
+
+func finalOrderStatus(status tables.OrderStatus) bool {
+	return status == tables.OrderStatusSuccess || status == tables.OrderStatusRefunded || status == tables.OrderStatusFail
+}
@@ -0,0 +1,231 @@
+package runner
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/inscription-c/cins/pkg/signal"
+	"github.com/inscription-c/explorer-api/log"
+	"github.com/inscription-c/explorer-api/tables"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	// defaultWebhookMaxAttempts is how many times a delivery is attempted by default before it fails.
+	defaultWebhookMaxAttempts = 10
+	// webhookRetryDelay is the delay before the first retry, it doubles on every attempt up to webhookMaxRetryDelay.
+	webhookRetryDelay    = time.Second * 10
+	webhookMaxRetryDelay = time.Hour
+	// webhookBatchSize is how many deliveries are sent in a round.
+	webhookBatchSize = 100
+	// WebhookSignatureHeader holds the hex HMAC-SHA256 of "<timestamp>.<request body>" keyed by the callback secret
+	// of the order, which is returned once when the order is created.
+	WebhookSignatureHeader = "X-Signature"
+	// WebhookTimestampHeader holds the unix time the request is sent at, receivers reject old ones to stop replays.
+	WebhookTimestampHeader = "X-Timestamp"
+)
+
+// WebhookPayload is the body of a callback request, it's sent on every event of an order with the status
+// of the order after the event.
+type WebhookPayload struct {
+	OrderId       string             `json:"order_id"`
+	Status        tables.OrderStatus `json:"status"`
+	InscriptionId string             `json:"inscription_id,omitempty"`
+	CommitTxId    string             `json:"commit_tx_id,omitempty"`
+	RevealTxId    string             `json:"reveal_tx_id,omitempty"`
+	RefundTxId    string             `json:"refund_tx_id,omitempty"`
+	Event         *WebhookEvent      `json:"event"`
+	Timestamp     int64              `json:"timestamp"`
+}
+
+// WebhookEvent is the event of an order that a callback request is sent for.
+type WebhookEvent struct {
+	Type    tables.OrderEventType `json:"type"`
+	Height  uint32                `json:"height,omitempty"`
+	TxId    string                `json:"tx_id,omitempty"`
+	Amount  int64                 `json:"amount,omitempty"`
+	Message string                `json:"message,omitempty"`
+}
+
+// Webhooks posts the events of the orders with a callback url to the callback url. The events are queued when
+// they're recorded, the requests are signed by the callback secret of the order and are only sent to public addresses.
+func (b *Runner) Webhooks() {
+	b.Go(func() error {
+		client := newWebhookClient()
+		ticker := time.NewTicker(time.Second * 2)
+		defer ticker.Stop()
+		for range ticker.C {
+			select {
+			case <-signal.InterruptChannel:
+				return nil
+			default:
+				if err := b.deliverWebhooks(client); err != nil {
+					log.Log.Errorf("deliverWebhooks err: %s", err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// newWebhookClient return a client that doesn't follow redirects or use a proxy, and that refuses to connect
+// to addresses that are not public. The address is checked when the connection is made, after the host is resolved.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: time.Second * 5,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return fmt.Errorf("callback address %s is not public", host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout:   time.Second * 10,
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// publicIP reports whether an ip is a public unicast address.
+func publicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast()
+}
+
+func (b *Runner) deliverWebhooks(client *http.Client) error {
+	deliveries, err := b.db.FindDueWebhookDeliveries(time.Now(), webhookBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, delivery := range deliveries {
+		order, err := b.db.GetInscribeOrderByOrderId(delivery.OrderId)
+		if err != nil {
+			return err
+		}
+		// the orders created before the callbacks were signed by a secret of their own have none
+		if order.CallbackSecret == "" {
+			delivery.Status = tables.WebhookDeliveryStatusFailed
+			delivery.LastError = "the order has no callback secret"
+			if err := b.db.Save(delivery).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		secret, err := b.keyring.Decrypt(order.CallbackSecret)
+		if err != nil {
+			return fmt.Errorf("order %s: %w", order.OrderId, err)
+		}
+		if delivery.Payload == "" {
+			payload, err := b.webhookPayload(&order, delivery)
+			if err != nil {
+				return err
+			}
+			delivery.Payload = payload
+		}
+		delivery.Attempts++
+		code, err := b.postWebhook(client, delivery, secret)
+		delivery.ResponseCode = code
+		if err == nil {
+			delivery.Status = tables.WebhookDeliveryStatusSuccess
+			delivery.LastError = ""
+		} else {
+			delivery.LastError = eventMessage(err)
+			if delivery.Attempts >= b.webhookMaxAttempts {
+				delivery.Status = tables.WebhookDeliveryStatusFailed
+				log.Log.Warn("WebhookDeliveryFailed", delivery.Id, delivery.OrderId, delivery.LastError)
+			} else {
+				delivery.NextAttemptAt = time.Now().Add(webhookBackoff(delivery.Attempts))
+			}
+		}
+		if err := b.db.Save(delivery).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// webhookPayload return the payload of a delivery made of its event and its order.
+func (b *Runner) webhookPayload(order *tables.InscribeOrder, delivery *tables.WebhookDelivery) (string, error) {
+	event, err := b.db.GetInscribeOrderEventById(delivery.EventId)
+	if err != nil {
+		return "", err
+	}
+	payload := &WebhookPayload{
+		OrderId:    delivery.OrderId,
+		Status:     event.Status,
+		CommitTxId: order.CommitTxId,
+		RevealTxId: order.RevealTxId,
+		RefundTxId: order.RefundTxId,
+		Event: &WebhookEvent{
+			Type:    event.Type,
+			Height:  event.Height,
+			TxId:    event.TxId,
+			Amount:  event.Amount,
+			Message: event.Message,
+		},
+		Timestamp: event.CreatedAt.Unix(),
+	}
+	if order.InscriptionId.TxId != "" {
+		payload.InscriptionId = order.InscriptionId.String()
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// postWebhook posts a delivery to its url signed by the callback secret of its order,
+// the request fails unless the response status is 2xx.
+func (b *Runner) postWebhook(client *http.Client, delivery *tables.WebhookDelivery, secret []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, delivery.Url, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, webhookSignature(secret, timestamp, delivery.Payload))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// webhookSignature return the hex HMAC-SHA256 of a payload sent at a unix time keyed by a callback secret.
+func webhookSignature(secret []byte, timestamp int64, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff return the delay before the next attempt of a delivery after a number of attempts.
+func webhookBackoff(attempts int) time.Duration {
+	delay := webhookRetryDelay
+	for i := 1; i < attempts && delay < webhookMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > webhookMaxRetryDelay {
+		delay = webhookMaxRetryDelay
+	}
+	return delay
+}
@@ -0,0 +1,44 @@
+package runner
+
+import (
+	"net"
+	"testing"
+)
+
+func TestPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "8.8.8.8", want: true},
+		{ip: "2001:4860:4860::8888", want: true},
+		{ip: "127.0.0.1", want: false},
+		{ip: "::1", want: false},
+		{ip: "10.0.0.1", want: false},
+		{ip: "172.16.0.1", want: false},
+		{ip: "192.168.1.1", want: false},
+		{ip: "fd00::1", want: false},
+		{ip: "169.254.169.254", want: false},
+		{ip: "fe80::1", want: false},
+		{ip: "0.0.0.0", want: false},
+		{ip: "224.0.0.1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := publicIP(net.ParseIP(tt.ip)); got != tt.want {
+				t.Errorf("publicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookSignature(t *testing.T) {
+	got := webhookSignature([]byte("secret"), 1700000000, `{"order_id":"a"}`)
+	want := "c9e831a55cb031d187ac2faf4a8919b288afd93c24628e6bdea98fc17bba2a14"
+	if got != want {
+		t.Errorf("webhookSignature() = %s, want %s", got, want)
+	}
+	if other := webhookSignature([]byte("secret"), 1700000001, `{"order_id":"a"}`); other == got {
+		t.Error("webhookSignature() doesn't sign the timestamp")
+	}
+}
@@ -0,0 +1,168 @@
+package stream
+
+import (
+	"sync"
+)
+
+type EventType string
+
+const (
+	EventTypeBlock       EventType = "block"
+	EventTypeInscription EventType = "inscription"
+	EventTypeOrder       EventType = "order"
+)
+
+// Event is a message pushed to the subscribers, it's matched to the topics of a subscriber by its addresses,
+// tickers and order id.
+type Event struct {
+	Type EventType   `json:"type"`
+	Data interface{} `json:"data"`
+
+	addresses []string
+	ticker    string
+	orderId   string
+}
+
+// NewBlockEvent creates the event of a new block, it's sent to every subscriber of blocks.
+func NewBlockEvent(data interface{}) *Event {
+	return &Event{Type: EventTypeBlock, Data: data}
+}
+
+// NewInscriptionEvent creates the event of a new inscription of an owner, ticker is set for c-brc-20 inscriptions.
+func NewInscriptionEvent(data interface{}, owner, ticker string) *Event {
+	return &Event{Type: EventTypeInscription, Data: data, addresses: []string{owner}, ticker: ticker}
+}
+
+// NewOrderEvent creates the event of a status change of an order.
+func NewOrderEvent(data interface{}, orderId string, addresses ...string) *Event {
+	return &Event{Type: EventTypeOrder, Data: data, addresses: addresses, orderId: orderId}
+}
+
+// Filter is the topics of a subscriber. An empty Types matches every type, and the addresses, tickers and order ids
+// narrow the inscription and order events, an event is sent if it matches any of them.
+// The order events are only sent to the subscribers of their order id or of one of their addresses,
+// so that the ids and addresses of the orders are not sent to everyone.
+type Filter struct {
+	Types     map[EventType]bool
+	Addresses map[string]bool
+	Tickers   map[string]bool
+	OrderIds  map[string]bool
+}
+
+// Match return whether an event is sent to the subscriber of the filter.
+func (f *Filter) Match(e *Event) bool {
+	if len(f.Types) > 0 && !f.Types[e.Type] {
+		return false
+	}
+	if e.Type == EventTypeBlock {
+		return true
+	}
+	for _, address := range e.addresses {
+		if f.Addresses[address] {
+			return true
+		}
+	}
+	if e.Type == EventTypeOrder {
+		return e.orderId != "" && f.OrderIds[e.orderId]
+	}
+	if len(f.Addresses) == 0 && len(f.Tickers) == 0 && len(f.OrderIds) == 0 {
+		return true
+	}
+	return e.ticker != "" && f.Tickers[e.ticker]
+}
+
+// Subscriber receives the events that match its filter. A subscriber that doesn't keep up with its events
+// is dropped by the hub, Done is closed when that happens.
+type Subscriber struct {
+	filter *Filter
+	events chan *Event
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Events return the channel of the events of the subscriber.
+func (s *Subscriber) Events() <-chan *Event {
+	return s.events
+}
+
+// Done return a channel that's closed when the subscriber is dropped for being too slow.
+func (s *Subscriber) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *Subscriber) drop() {
+	s.once.Do(func() {
+		close(s.done)
+	})
+}
+
+// Hub fans out the events to the subscribers. Every subscriber has a buffer of bufferSize events,
+// a full buffer drops the subscriber instead of blocking the publisher.
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[*Subscriber]struct{}
+	bufferSize  int
+}
+
+// defaultBufferSize is how many events a subscriber may fall behind by default.
+const defaultBufferSize = 256
+
+// NewHub creates a hub whose subscribers buffer up to bufferSize events, 0 uses defaultBufferSize.
+func NewHub(bufferSize int) *Hub {
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+	return &Hub{
+		subscribers: make(map[*Subscriber]struct{}),
+		bufferSize:  bufferSize,
+	}
+}
+
+// Subscribe adds a subscriber of the events that match the filter, the caller must Unsubscribe it when it's done.
+func (h *Hub) Subscribe(filter *Filter) *Subscriber {
+	s := &Subscriber{
+		filter: filter,
+		events: make(chan *Event, h.bufferSize),
+		done:   make(chan struct{}),
+	}
+	h.mu.Lock()
+	h.subscribers[s] = struct{}{}
+	h.mu.Unlock()
+	return s
+}
+
+// Unsubscribe removes a subscriber.
+func (h *Hub) Unsubscribe(s *Subscriber) {
+	h.mu.Lock()
+	delete(h.subscribers, s)
+	h.mu.Unlock()
+	s.drop()
+}
+
+// Subscribers return the number of subscribers.
+func (h *Hub) Subscribers() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers)
+}
+
+// Publish sends an event to the subscribers that match it without blocking.
+func (h *Hub) Publish(e *Event) {
+	slow := make([]*Subscriber, 0)
+	h.mu.RLock()
+	for s := range h.subscribers {
+		if !s.filter.Match(e) {
+			continue
+		}
+		select {
+		case s.events <- e:
+		default:
+			slow = append(slow, s)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, s := range slow {
+		h.Unsubscribe(s)
+	}
+}
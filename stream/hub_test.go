@@ -0,0 +1,106 @@
+package stream
+
+import "testing"
+
+func TestFilterMatch(t *testing.T) {
+	block := NewBlockEvent(nil)
+	inscription := NewInscriptionEvent(nil, "bc1powner", "ordi")
+	plainInscription := NewInscriptionEvent(nil, "bc1powner", "")
+	order := NewOrderEvent(nil, "order1", "bc1preceive")
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		event  *Event
+		want   bool
+	}{
+		{"empty filter matches blocks", &Filter{}, block, true},
+		{"empty filter matches inscriptions", &Filter{}, inscription, true},
+		{"empty filter doesn't match orders", &Filter{}, order, false},
+		{"type filter", &Filter{Types: map[EventType]bool{EventTypeOrder: true}}, inscription, false},
+		{"type filter doesn't match orders without topics", &Filter{Types: map[EventType]bool{EventTypeOrder: true}}, order, false},
+		{"type filter matches", &Filter{
+			Types:    map[EventType]bool{EventTypeOrder: true},
+			OrderIds: map[string]bool{"order1": true},
+		}, order, true},
+		{"ticker doesn't match orders", &Filter{Tickers: map[string]bool{"ordi": true}}, order, false},
+		{"topics don't narrow blocks", &Filter{Addresses: map[string]bool{"bc1pother": true}}, block, true},
+		{"address", &Filter{Addresses: map[string]bool{"bc1powner": true}}, inscription, true},
+		{"other address", &Filter{Addresses: map[string]bool{"bc1pother": true}}, inscription, false},
+		{"receive address of an order", &Filter{Addresses: map[string]bool{"bc1preceive": true}}, order, true},
+		{"ticker", &Filter{Tickers: map[string]bool{"ordi": true}}, inscription, true},
+		{"other ticker", &Filter{Tickers: map[string]bool{"sats": true}}, inscription, false},
+		{"ticker of a plain inscription", &Filter{Tickers: map[string]bool{"ordi": true}}, plainInscription, false},
+		{"order id", &Filter{OrderIds: map[string]bool{"order1": true}}, order, true},
+		{"other order id", &Filter{OrderIds: map[string]bool{"order2": true}}, order, false},
+		{"order id doesn't match inscriptions", &Filter{OrderIds: map[string]bool{"order1": true}}, inscription, false},
+		{"any topic matches", &Filter{
+			Addresses: map[string]bool{"bc1pother": true},
+			OrderIds:  map[string]bool{"order1": true},
+		}, order, true},
+		{"type and topic", &Filter{
+			Types:     map[EventType]bool{EventTypeInscription: true},
+			Addresses: map[string]bool{"bc1preceive": true},
+		}, order, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(tt.event); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHubPublish(t *testing.T) {
+	hub := NewHub(2)
+	all := hub.Subscribe(&Filter{OrderIds: map[string]bool{"order1": true}})
+	orders := hub.Subscribe(&Filter{
+		Types:    map[EventType]bool{EventTypeOrder: true},
+		OrderIds: map[string]bool{"order1": true},
+	})
+	if hub.Subscribers() != 2 {
+		t.Fatalf("subscribers %d, want 2", hub.Subscribers())
+	}
+
+	hub.Publish(NewBlockEvent(1))
+	hub.Publish(NewBlockEvent(2))
+	if len(all.Events()) != 2 || len(orders.Events()) != 0 {
+		t.Fatalf("buffered %d and %d events, want 2 and 0", len(all.Events()), len(orders.Events()))
+	}
+
+	// the buffer of all is full, it's dropped instead of blocking the publisher
+	hub.Publish(NewOrderEvent(3, "order1"))
+	select {
+	case <-all.Done():
+	default:
+		t.Fatal("slow subscriber is not dropped")
+	}
+	select {
+	case <-orders.Done():
+		t.Fatal("subscriber that keeps up is dropped")
+	default:
+	}
+	if hub.Subscribers() != 1 {
+		t.Fatalf("subscribers %d, want 1", hub.Subscribers())
+	}
+	if e := <-orders.Events(); e.Data != 3 {
+		t.Fatalf("event data %v, want 3", e.Data)
+	}
+
+	hub.Unsubscribe(orders)
+	hub.Unsubscribe(orders)
+	if hub.Subscribers() != 0 {
+		t.Fatalf("subscribers %d, want 0", hub.Subscribers())
+	}
+	hub.Publish(NewOrderEvent(4, "order1"))
+	if len(orders.Events()) != 0 {
+		t.Fatal("unsubscribed subscriber got an event")
+	}
+}
+
+func TestNewHubBufferSize(t *testing.T) {
+	if got := NewHub(0).bufferSize; got != defaultBufferSize {
+		t.Fatalf("buffer size %d, want %d", got, defaultBufferSize)
+	}
+}
@@ -0,0 +1,36 @@
+package tables
+
+import "time"
+
+// CBRC20Mint is a valid c-brc-20 mint. The indexer only records deploys,
+// so mints are parsed from the inscriptions by the explorer.
+type CBRC20Mint struct {
+	Id            uint64 `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"`
+	InscriptionId `gorm:"embedded"`
+	SequenceNum   int64     `gorm:"column:sequence_num;type:bigint;uniqueIndex:uk_sequence_num;default:0;NOT NULL"`
+	Height        uint32    `gorm:"column:height;type:int unsigned;index:idx_height;default:0;NOT NULL"`
+	TkID          string    `gorm:"column:tkid;type:varchar(255);index:idx_tkid;default:'';NOT NULL"` // deploy inscription id
+	Ticker        string    `gorm:"column:ticker;type:varchar(255);index:idx_ticker;default:'';NOT NULL"`
+	Amount        uint64    `gorm:"column:amount;type:bigint unsigned;default:0;NOT NULL"`
+	Owner         string    `gorm:"column:owner;type:varchar(255);index:idx_owner;default:'';NOT NULL"`
+	Timestamp     int64     `gorm:"column:timestamp;type:bigint;default:0;NOT NULL"`
+	CreatedAt     time.Time `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+	UpdatedAt     time.Time `gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+}
+
+func (m *CBRC20Mint) TableName() string {
+	return "cbrc20_mint"
+}
+
+// CBRC20ParserInfo is the last indexer blocks parsed for c-brc-20 mints, it is used to detect reorgs.
+type CBRC20ParserInfo struct {
+	Id        uint64    `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"`
+	Height    uint32    `gorm:"column:height;type:int unsigned;uniqueIndex:uk_height;default:0;NOT NULL"`
+	BlockHash string    `gorm:"column:block_hash;type:varchar(255);default:'';NOT NULL"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+	UpdatedAt time.Time `gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+}
+
+func (p *CBRC20ParserInfo) TableName() string {
+	return "cbrc20_parser_info"
+}
@@ -0,0 +1,25 @@
+package tables
+
+import "time"
+
+// CBRC20Token is a valid c-brc-20 deploy, only the first deploy of a ticker is a token.
+type CBRC20Token struct {
+	Id              uint64 `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"`
+	InscriptionId   `gorm:"embedded"`
+	TkID            string          `gorm:"column:tkid;type:varchar(255);uniqueIndex:uk_tkid;default:'';NOT NULL"` // deploy inscription id
+	SequenceNum     int64           `gorm:"column:sequence_num;type:bigint;index:idx_sequence_num;default:0;NOT NULL"`
+	Height          uint32          `gorm:"column:height;type:int unsigned;index:idx_height;default:0;NOT NULL"`
+	Ticker          string          `gorm:"column:ticker;type:varchar(255);uniqueIndex:uk_ticker;default:'';NOT NULL"`
+	Max             uint64          `gorm:"column:max;type:bigint unsigned;default:0;NOT NULL"`
+	Limit           uint64          `gorm:"column:limit;type:bigint unsigned;default:0;NOT NULL"`
+	Decimals        uint32          `gorm:"column:decimals;type:int unsigned;default:0;NOT NULL"`
+	CInsDescription CInsDescription `gorm:"embedded"`
+	Owner           string          `gorm:"column:owner;type:varchar(255);default:'';NOT NULL"`
+	Timestamp       int64           `gorm:"column:timestamp;type:bigint;default:0;NOT NULL"`
+	CreatedAt       time.Time       `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+	UpdatedAt       time.Time       `gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+}
+
+func (t *CBRC20Token) TableName() string {
+	return "cbrc20_token"
+}
@@ -0,0 +1,14 @@
+package tables
+
+import "time"
+
+// HDKeyIndex allocates the indexes of the reveal keys derived from the HD key, the id of a row is an index.
+// An index is never reused even if its order isn't saved.
+type HDKeyIndex struct {
+	Id        uint64    `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+}
+
+func (i *HDKeyIndex) TableName() string {
+	return "hd_key_index"
+}
@@ -3,17 +3,22 @@ package tables
 import (
 	"crypto/md5"
 	"fmt"
+	"strings"
 	"time"
 )
 
 type OrderStatus int
 
 const (
+	OrderStatusExpired      OrderStatus = -3
 	OrderStatusFeeNotEnough OrderStatus = -2
 	OrderStatusFail         OrderStatus = -1
 	OrderStatusDefault      OrderStatus = 0
 	OrderStatusRevealSend   OrderStatus = 1
 	OrderStatusSuccess      OrderStatus = 2
+	OrderStatusRefunding    OrderStatus = 3
+	OrderStatusRefunded     OrderStatus = 4
+	OrderStatusCommitSeen   OrderStatus = 5 // the commit tx is in the mempool
 )
 
 type InscribeOrder struct {
@@ -21,12 +26,25 @@ type InscribeOrder struct {
 	OrderId        string `gorm:"column:order_id;type:varchar(255);index:idx_order_id;default:'';NOT NULL"`
 	InscriptionId  `gorm:"embedded"`
 	RevealAddress  string      `gorm:"column:reveal_address;type:varchar(255);index:idx_reveal_address;default:;NOT NULL"`
-	RevealPriKey   string      `gorm:"column:reveal_pri_key;type:varchar(255);default:;NOT NULL"`
+	RevealPriKey   string      `gorm:"column:reveal_pri_key;type:varchar(255);default:;NOT NULL"`                           // encrypted by the keystore, or plaintext hex without a master key
+	RevealKeyPath  string      `gorm:"column:reveal_key_path;type:varchar(64);index:idx_reveal_key_path;default:;NOT NULL"` // derivation path of the reveal key, RevealPriKey is empty if it's set
 	RevealTxId     string      `gorm:"column:reveal_tx_id;type:varchar(255);index:idx_reveal_tx_id;default:;NOT NULL"`
 	RevealTxRaw    string      `gorm:"column:reveal_tx_raw;type:mediumtext;default:;NOT NULL"`
 	RevealTxValue  int64       `gorm:"column:reveal_tx_value;type:bigint;default:0;NOT NULL"`
+	FeeRate        int64       `gorm:"column:fee_rate;type:bigint;default:0;NOT NULL"`     // sat/kvB of the reveal tx
+	ChangeValue    int64       `gorm:"column:change_value;type:bigint;default:0;NOT NULL"` // overpayment returned by the reveal tx
 	ReceiveAddress string      `gorm:"column:receive_address;type:varchar(255);index:idx_receive_address;default:;NOT NULL"`
 	CommitTxId     string      `gorm:"column:commit_tx_id;type:varchar(255);index:idx_commit_tx_id;default:;NOT NULL"`
+	CommitTxIndex  uint32      `gorm:"column:commit_tx_index;type:int unsigned;default:0;NOT NULL"`
+	CommitTxValue  int64       `gorm:"column:commit_tx_value;type:bigint;default:0;NOT NULL"`
+	RefundAddress  string      `gorm:"column:refund_address;type:varchar(255);default:;NOT NULL"`
+	RefundTxId     string      `gorm:"column:refund_tx_id;type:varchar(255);default:;NOT NULL"`
+	RevealSentAt   time.Time   `gorm:"column:reveal_sent_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+	BumpFeeRate    int64       `gorm:"column:bump_fee_rate;type:bigint;default:0;NOT NULL"` // requested sat/kvB of a replacement reveal tx
+	BumpCount      int         `gorm:"column:bump_count;type:int;default:0;NOT NULL"`
+	ReplacedTxIds  string      `gorm:"column:replaced_tx_ids;type:text;NOT NULL"` // comma separated ids of the replaced reveal txs
+	CallbackUrl    string      `gorm:"column:callback_url;type:varchar(1024);default:;NOT NULL"`
+	CallbackSecret string      `gorm:"column:callback_secret;type:varchar(255);default:;NOT NULL"` // HMAC key of the callbacks, encrypted by the keystore, or plaintext hex without a master key
 	Status         OrderStatus `gorm:"column:status;type:int;default:0;NOT NULL"`
 	CreatedAt      time.Time   `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
 	UpdatedAt      time.Time   `gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
@@ -40,3 +58,28 @@ func (o *InscribeOrder) InitOrderId() {
 	orderId := fmt.Sprintf("%s%s%d", o.RevealAddress, o.ReceiveAddress, time.Now().UnixMilli())
 	o.OrderId = fmt.Sprintf("%x", md5.Sum([]byte(orderId)))
 }
+
+// taprootKeyPathInputVSize is the virtual size of a taproot key path input, rounded up.
+const taprootKeyPathInputVSize = 58
+
+// RequiredValue return the value the reveal tx needs when it spends a number of payment outputs, the fee rate is in sat/kvB,
+// every payment after the first one is spent by the key path and pays the fee of its own input.
+func (o *InscribeOrder) RequiredValue(payments int) int64 {
+	if payments <= 1 {
+		return o.RevealTxValue
+	}
+	inputsFee := int64(payments-1) * taprootKeyPathInputVSize * o.FeeRate
+	return o.RevealTxValue + (inputsFee+999)/1000
+}
+
+// RevealTxIds return the ids of the replaced reveal txs and the current one, any of them may be confirmed.
+func (o *InscribeOrder) RevealTxIds() []string {
+	txIds := make([]string, 0)
+	if o.ReplacedTxIds != "" {
+		txIds = append(txIds, strings.Split(o.ReplacedTxIds, ",")...)
+	}
+	if o.RevealTxId != "" {
+		txIds = append(txIds, o.RevealTxId)
+	}
+	return txIds
+}
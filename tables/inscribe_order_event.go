@@ -0,0 +1,36 @@
+package tables
+
+import "time"
+
+type OrderEventType string
+
+const (
+	OrderEventCreated        OrderEventType = "created"
+	OrderEventCommitSeen     OrderEventType = "commit_seen"
+	OrderEventPayment        OrderEventType = "payment"
+	OrderEventFeeNotEnough   OrderEventType = "fee_not_enough"
+	OrderEventRevealSent     OrderEventType = "reveal_sent"
+	OrderEventRevealFailed   OrderEventType = "reveal_failed"
+	OrderEventRevealReplaced OrderEventType = "reveal_replaced"
+	OrderEventInscribed      OrderEventType = "inscribed"
+	OrderEventExpired        OrderEventType = "expired"
+	OrderEventRefundSent     OrderEventType = "refund_sent"
+	OrderEventRefunded       OrderEventType = "refunded"
+)
+
+// InscribeOrderEvent is an entry of the timeline of an order, the events of a parsed block are removed if the block is reorged.
+type InscribeOrderEvent struct {
+	Id        uint64         `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"`
+	OrderId   string         `gorm:"column:order_id;type:varchar(255);index:idx_order_id;default:'';NOT NULL"`
+	Type      OrderEventType `gorm:"column:type;type:varchar(64);default:'';NOT NULL"`
+	Status    OrderStatus    `gorm:"column:status;type:int;default:0;NOT NULL"` // the status of the order after the event
+	Height    uint32         `gorm:"column:height;type:int unsigned;default:0;NOT NULL"`
+	TxId      string         `gorm:"column:tx_id;type:varchar(255);default:'';NOT NULL"`
+	Amount    int64          `gorm:"column:amount;type:bigint;default:0;NOT NULL"`
+	Message   string         `gorm:"column:message;type:varchar(1024);default:'';NOT NULL"`
+	CreatedAt time.Time      `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+}
+
+func (e *InscribeOrderEvent) TableName() string {
+	return "inscribe_order_event"
+}
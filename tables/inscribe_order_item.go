@@ -0,0 +1,19 @@
+package tables
+
+import "time"
+
+// InscribeOrderItem is an inscription of a batch inscribe order, the inscription id is set once the reveal transaction is inscribed.
+type InscribeOrderItem struct {
+	Id             uint64 `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"`
+	OrderId        string `gorm:"column:order_id;type:varchar(255);uniqueIndex:uk_order_id_index;default:'';NOT NULL"`
+	Index          uint32 `gorm:"column:index;type:int unsigned;uniqueIndex:uk_order_id_index;default:0;NOT NULL"` // envelope and output index of the reveal tx
+	InscriptionId  `gorm:"embedded"`
+	ContentType    string    `gorm:"column:content_type;type:varchar(255);default:'';NOT NULL"`
+	ReceiveAddress string    `gorm:"column:receive_address;type:varchar(255);index:idx_receive_address;default:'';NOT NULL"`
+	CreatedAt      time.Time `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+	UpdatedAt      time.Time `gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+}
+
+func (i *InscribeOrderItem) TableName() string {
+	return "inscribe_order_item"
+}
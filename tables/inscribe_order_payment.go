@@ -0,0 +1,19 @@
+package tables
+
+import "time"
+
+// InscribeOrderPayment is an output paid to the reveal address of an order, an order may be paid by many outputs.
+type InscribeOrderPayment struct {
+	Id        uint64    `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"`
+	OrderId   string    `gorm:"column:order_id;type:varchar(255);index:idx_order_id;default:'';NOT NULL"`
+	TxId      string    `gorm:"column:tx_id;type:varchar(255);uniqueIndex:uk_outpoint;default:'';NOT NULL"`
+	Index     uint32    `gorm:"column:index;type:int unsigned;uniqueIndex:uk_outpoint;default:0;NOT NULL"`
+	Value     int64     `gorm:"column:value;type:bigint;default:0;NOT NULL"`
+	Height    uint32    `gorm:"column:height;type:int unsigned;default:0;NOT NULL"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+	UpdatedAt time.Time `gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+}
+
+func (p *InscribeOrderPayment) TableName() string {
+	return "inscribe_order_payment"
+}
@@ -0,0 +1,28 @@
+package tables
+
+import "testing"
+
+func TestInscribeOrderRequiredValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		feeRate  int64
+		payments int
+		want     int64
+	}{
+		{name: "no payment", feeRate: 2000, payments: 0, want: 10000},
+		{name: "one payment", feeRate: 2000, payments: 1, want: 10000},
+		{name: "two payments", feeRate: 2000, payments: 2, want: 10116},
+		{name: "three payments", feeRate: 2000, payments: 3, want: 10232},
+		{name: "fee rounded up", feeRate: 1001, payments: 2, want: 10059},
+		{name: "min relay fee rate", feeRate: 1000, payments: 2, want: 10058},
+		{name: "legacy order without fee rate", feeRate: 0, payments: 2, want: 10000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &InscribeOrder{RevealTxValue: 10000, FeeRate: tt.feeRate}
+			if got := order.RequiredValue(tt.payments); got != tt.want {
+				t.Errorf("RequiredValue(%d) = %d, want %d", tt.payments, got, tt.want)
+			}
+		})
+	}
+}
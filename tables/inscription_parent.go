@@ -0,0 +1,32 @@
+package tables
+
+import "time"
+
+// InscriptionParent is a parent of an inscription. The indexer doesn't record the parent tags,
+// so they are parsed from the reveal txs by the explorer.
+type InscriptionParent struct {
+	Id            uint64 `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"`
+	InscriptionId `gorm:"embedded"`
+	SequenceNum   int64     `gorm:"column:sequence_num;type:bigint;index:idx_sequence_num;default:0;NOT NULL"`
+	ParentId      string    `gorm:"column:parent_id;type:varchar(255);index:idx_parent_id;default:'';NOT NULL"`
+	Height        uint32    `gorm:"column:height;type:int unsigned;index:idx_height;default:0;NOT NULL"`
+	CreatedAt     time.Time `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+	UpdatedAt     time.Time `gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+}
+
+func (p *InscriptionParent) TableName() string {
+	return "inscription_parent"
+}
+
+// ParentParserInfo is the last indexer blocks parsed for parent tags, it is used to detect reorgs.
+type ParentParserInfo struct {
+	Id        uint64    `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"`
+	Height    uint32    `gorm:"column:height;type:int unsigned;uniqueIndex:uk_height;default:0;NOT NULL"`
+	BlockHash string    `gorm:"column:block_hash;type:varchar(255);default:'';NOT NULL"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+	UpdatedAt time.Time `gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+}
+
+func (p *ParentParserInfo) TableName() string {
+	return "parent_parser_info"
+}
@@ -0,0 +1,31 @@
+package tables
+
+import "time"
+
+// InscriptionRarity is the rarity of the sat of an inscription, only the inscriptions on uncommon or
+// better sats are recorded, so that the rarity filter doesn't compute the rarity of every sat in a query.
+type InscriptionRarity struct {
+	Id          uint64    `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"`
+	SequenceNum int64     `gorm:"column:sequence_num;type:bigint;uniqueIndex:uk_sequence_num;default:0;NOT NULL"`
+	Rarity      uint8     `gorm:"column:rarity;type:tinyint unsigned;index:idx_rarity;default:0;NOT NULL"`
+	Height      uint32    `gorm:"column:height;type:int unsigned;index:idx_height;default:0;NOT NULL"`
+	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+}
+
+func (r *InscriptionRarity) TableName() string {
+	return "inscription_rarity"
+}
+
+// RarityParserInfo is the last indexer blocks parsed for rarities, it is used to detect reorgs.
+type RarityParserInfo struct {
+	Id        uint64    `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"`
+	Height    uint32    `gorm:"column:height;type:int unsigned;uniqueIndex:uk_height;default:0;NOT NULL"`
+	BlockHash string    `gorm:"column:block_hash;type:varchar(255);default:'';NOT NULL"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+	UpdatedAt time.Time `gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+}
+
+func (r *RarityParserInfo) TableName() string {
+	return "rarity_parser_info"
+}
@@ -5,4 +5,16 @@ var Tables = []interface{}{
 	&BlockParserInfo{},
 	&UndoLog{},
 	&SavePoint{},
+	&CBRC20Token{},
+	&CBRC20Mint{},
+	&CBRC20ParserInfo{},
+	&InscribeOrderItem{},
+	&InscribeOrderPayment{},
+	&InscribeOrderEvent{},
+	&WebhookDelivery{},
+	&HDKeyIndex{},
+	&InscriptionParent{},
+	&ParentParserInfo{},
+	&InscriptionRarity{},
+	&RarityParserInfo{},
 }
@@ -0,0 +1,33 @@
+package tables
+
+import "time"
+
+type WebhookDeliveryStatus int
+
+const (
+	WebhookDeliveryStatusFailed  WebhookDeliveryStatus = -1
+	WebhookDeliveryStatusPending WebhookDeliveryStatus = 0
+	WebhookDeliveryStatusSuccess WebhookDeliveryStatus = 1
+)
+
+// WebhookDelivery is an event of an order queued for its callback url, failed attempts are retried with backoff.
+// The payload is made on the first attempt, so that it has the order saved with the event,
+// the status is the one recorded on the event so that a late first attempt doesn't report a later status.
+type WebhookDelivery struct {
+	Id            uint64                `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"`
+	OrderId       string                `gorm:"column:order_id;type:varchar(255);index:idx_order_id;default:'';NOT NULL"`
+	EventId       uint64                `gorm:"column:event_id;type:bigint unsigned;index:idx_event_id;default:0;NOT NULL"`
+	Url           string                `gorm:"column:url;type:varchar(1024);default:'';NOT NULL"`
+	Payload       string                `gorm:"column:payload;type:text;NOT NULL"`
+	Status        WebhookDeliveryStatus `gorm:"column:status;type:int;index:idx_status_next_attempt_at;default:0;NOT NULL"`
+	Attempts      int                   `gorm:"column:attempts;type:int;default:0;NOT NULL"`
+	NextAttemptAt time.Time             `gorm:"column:next_attempt_at;type:timestamp;index:idx_status_next_attempt_at;default:CURRENT_TIMESTAMP;NOT NULL"`
+	ResponseCode  int                   `gorm:"column:response_code;type:int;default:0;NOT NULL"`
+	LastError     string                `gorm:"column:last_error;type:varchar(1024);default:'';NOT NULL"`
+	CreatedAt     time.Time             `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+	UpdatedAt     time.Time             `gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+}
+
+func (d *WebhookDelivery) TableName() string {
+	return "webhook_delivery"
+}